@@ -1,3 +1,3 @@
-module text-comparison-tool
+module github.com/CarlosGomezCalzado/text-comparison-tool
 
 go 1.22.0
@@ -1,533 +1,417 @@
 /*
-This Go code performs text comparison between two strings, identifying added, deleted, and modified content. It utilizes a rolling hash algorithm for efficient text search.
-
-Package main implements a text comparison tool.
-
-The TextSearch struct represents a search context for sliding window hashing.
-
-The CustomError struct defines a custom error type for handling errors.
+This Go code implements the command line interface of the text comparison tool. The comparison itself lives in the textcompare package.
 
 The following functions are implemented:
 
-1. GetWindowString:
+1. readLine:
    - Parameters: None
-   - Results: Returns the current window of text.
-   - Description: Returns the current window of text being analyzed.
+   - Results: User input string, whether a line was read (bool)
+   - Description: Reads a line of input from standard input, reporting false once the input is exhausted.
 
-2. Slide:
+2. getInput:
    - Parameters: None
-   - Results: Returns a custom error, the updated hash, and the current window of text.
-   - Description: Slides the window to calculate the hash of the next text segment.
+   - Results: Old text, updated text, whether both were read (bool)
+   - Description: Gets user input for text comparison.
 
-3. GetHash:
-   - Parameters: None
-   - Results: Returns the current hash value.
-   - Description: Retrieves the current hash value of the text.
+3. readFiles:
+   - Parameters: oldPath (string), newPath (string)
+   - Results: Old text, updated text, error
+   - Description: Reads the full content of the two files to compare.
 
-4. CreateBuffer:
-   - Parameters: input (string), windowSize (int)
+4. displayResult:
+   - Parameters: old (string), updated (string), result (string)
    - Results: None
-   - Description: Initializes the text buffer with a specific window size.
+   - Description: Displays the old text, updated text, and comparison result on the standard output.
 
-5. SetStart:
-   - Parameters: index (int), window (int)
+5. WriteResult:
+   - Parameters: w (io.Writer), old (string), updated (string), result (string)
    - Results: None
-   - Description: Sets the starting point of the window for hashing.
-
-6. SearchFirstDif:
-   - Parameters: text1 (string), text2 (string), windowSize (int)
-   - Results: Equal text until first difference, index of first difference, boolean indicating completion, error
-   - Description: Searches for the first difference between two texts.
-
-7. searchAddedContent:
-   - Parameters: text1 (string), text2 (string), windowSize (int)
-   - Results: Added content, old index, new index, boolean indicating completion
-   - Description: Searches for added content in the updated text.
-
-8. searchDeletedContent:
-   - Parameters: text1 (string), text2 (string), windowSize (int)
-   - Results: Deleted content, old index, new index, boolean indicating completion
-   - Description: Searches for deleted content in the updated text.
-
-9. searchModifiedContent:
-   - Parameters: text1 (string), text2 (string), windowSize (int)
-   - Results: Previous content, new content, old index, new index, boolean indicating completion
-   - Description: Searches for modified content in the updated text.
-
-10. checkString:
-    - Parameters: old (string), updated (string), windowSize (int), oldGeneralIndex (int)
-    - Results: Comparison result
-    - Description: Recursively checks for differences between two texts.
-
-11. readLine:
-    - Parameters: None
-    - Results: User input string
-    - Description: Reads a line of input from standard input.
-
-12. getInput:
-    - Parameters: None
-    - Results: Old text, updated text, window size
-    - Description: Gets user input for text comparison.
-
-13. displayResult:
-    - Parameters: old (string), updated (string), result (string)
-    - Results: None
-    - Description: Displays the old text, updated text, and comparison result.
-
-14. main:
-    - Parameters: None
-    - Results: None
-    - Description: Orchestrates the text comparison process, obtaining input, performing comparison, and displaying results.
+   - Description: Writes the old text, updated text, and comparison result to any writer.
+
+6. printJSON:
+   - Parameters: edits ([]textcompare.Edit)
+   - Results: error
+   - Description: Prints the comparison result as a JSON array of edits.
+
+7. useColor:
+   - Parameters: mode (string), noColor (bool), terminal (bool)
+   - Results: Whether to color the output (bool), error
+   - Description: Resolves the -color flag, where auto colors only a terminal and the NO_COLOR environment variable disables colors.
+
+8. isTerminal:
+   - Parameters: f (*os.File)
+   - Results: Whether the file is a terminal (bool)
+   - Description: Reports whether a file is a character device such as a terminal.
+
+9. formatEdits:
+   - Parameters: edits ([]textcompare.Edit), color (bool)
+   - Results: Delta (string)
+   - Description: Renders the edits as the textual delta, colored with ANSI escape codes when requested.
+
+10. isFlagSet:
+   - Parameters: flags (*flag.FlagSet), name (string)
+   - Results: Whether the flag was given (bool)
+   - Description: Reports whether a flag was given on the command line rather than left to its default.
+
+11. applyDelta:
+   - Parameters: oldPath (string), deltaPath (string), outPath (string)
+   - Results: error
+   - Description: Applies the delta saved in a file to the old file and writes the updated text to outPath, or to standard output when it is empty.
+
+12. runApply:
+   - Parameters: args ([]string)
+   - Results: error
+   - Description: Parses the flags of the apply subcommand and applies the saved delta.
+
+13. readWindow:
+   - Parameters: old (string), updated (string), fallback (int)
+   - Results: Window size (int), whether a line was read (bool), error
+   - Description: Prompts for the window size of an interactive comparison, using fallback, or a size suggested from the texts when it is 0, for an empty answer.
+
+14. compare:
+   - Parameters: old (string), updated (string), windowSize (int), out (output)
+   - Results: Exit code (int)
+   - Description: Compares the two texts and prints the result in the selected output format.
+
+15. runREPL:
+   - Parameters: window (int), out (output)
+   - Results: Exit code (int)
+   - Description: Compares texts read from standard input one pair after another until the input ends, such as with Ctrl-D.
+     The exit code is 2 when any comparison of the session failed and 0 otherwise.
+
+16. run:
+   - Parameters: args ([]string)
+   - Results: Exit code (int)
+   - Description: Orchestrates the text comparison process, obtaining input, performing comparison, and displaying results.
+     When the -old and -new flags are given the texts are read from those files instead of prompting.
+     The -format flag selects between the textual delta, JSON output and the locations of the changes.
+     When the -window flag is omitted the window size is suggested from the length of the texts.
+     The -color flag selects whether the textual delta is colored.
+     The -linecol flag gives the positions of the textual delta as lines and columns of the old text.
+     The apply subcommand reconstructs the updated file from the old file and a saved delta.
+     The -repl flag keeps prompting for texts to compare until the input ends.
+     The exit code is 0 when the texts are identical, 1 when they differ and 2 on error.
+     Errors are written to standard error, so the standard output only holds the result.
+
+17. main:
+   - Parameters: None
+   - Results: None
+   - Description: Runs the command with the command line arguments and exits with its exit code.
 */
 
 package main
 
 import (
-	"fmt"
-	"math"
-	"strconv"
 	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+
+	"github.com/CarlosGomezCalzado/text-comparison-tool/textcompare"
 )
 
-type TextSearch struct {
-	buffer     string
-	hash       int
-	index      int
-	length     int
-	prime      int
-	windowSize int
-	lastError  error
-}
+// input buffers the standard input once, so the lines read by successive
+// prompts are not lost in the buffer of a previous one.
+var input = bufio.NewReader(os.Stdin)
 
-type CustomError struct {
-	message string
+func readLine() (string, bool) {
+	// This function reads a line of input, reporting false once the input is exhausted
+	line, err := input.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimSpace(line), true
 }
 
-func (e *CustomError) Error() string {
-	return e.message
-}
+func getInput() (string, string, bool) {
+	// This function gets user input for the two texts
+
+	// Prompt the user to enter the old text
+	fmt.Println("Enter the old text:")
+	old, ok := readLine()
+	if !ok {
+		return "", "", false
+	}
+
+	// Prompt the user to enter the updated text
+	fmt.Println("Enter the updated text:")
+	updated, ok := readLine()
 
-// Obtain current window string
-func (ts *TextSearch) GetWindowString() string {
-	return ts.buffer[ts.index:]
+	return old, updated, ok
 }
 
-// Slide the window to calculate the hash of the next text segment.
-func (ts *TextSearch) Slide() (*CustomError, int, string) {
-	if ts.index+ts.windowSize >= ts.length {
-		ts.lastError = &CustomError{message: "EOF"}
-		return ts.lastError.(*CustomError), ts.hash, ts.GetWindowString()
+// separator ends the prompts of a comparison before its result.
+const separator = "_______________________________________"
+
+func readFiles(oldPath, newPath string) (string, string, error) {
+	// This function loads the full content of the two files to compare
+	if oldPath == "" || newPath == "" {
+		return "", "", errors.New("both -old and -new must be provided")
 	}
-	// Remove the contribution of the oldest character.
-	ts.hash = (ts.hash - int(ts.buffer[ts.index])*int(math.Pow(256, float64(ts.windowSize-1)))) % ts.prime
-	if ts.hash < 0 {
-		ts.hash += ts.prime // Ensure that the result is positive
+	old, err := os.ReadFile(oldPath)
+	if err != nil {
+		return "", "", err
 	}
-
-	// Add the contribution of the new character
-	ts.hash = (ts.hash*256 + int(ts.buffer[ts.index+ts.windowSize])) % ts.prime
-	ts.index++
-	return nil, ts.hash, ts.GetWindowString()
+	updated, err := os.ReadFile(newPath)
+	if err != nil {
+		return "", "", err
+	}
+	return string(old), string(updated), nil
 }
 
-// Get the current hash of the text
-func (ts *TextSearch) GetHash() int {
-	return ts.hash
+func displayResult(old, updated, result string) {
+	// This function displays the old text, updated text, and comparison result
+	WriteResult(os.Stdout, old, updated, result)
 }
 
-// Create a text buffer with a specific window size
-func (ts *TextSearch) CreateBuffer(input string, windowSize int) {
-	ts.buffer = input
-	ts.hash = 0
-	ts.prime = 5381
-	ts.length = len(input)
-	ts.windowSize = windowSize
-	ts.lastError = nil
+func WriteResult(w io.Writer, old, updated, result string) {
+	// This function writes the old text, updated text, and comparison result to w
+	fmt.Fprintln(w, "Old text:", old)
+	fmt.Fprintln(w, "Updated text:", updated)
+	fmt.Fprintln(w, "Comparison result:")
+	fmt.Fprintln(w, result)
 }
 
-// Set the starting point of the window
-func (ts *TextSearch) SetStart(index, window int) {
-	ts.index = index
-	ts.windowSize = window
-	ts.hash = 0
-	ts.lastError = nil
-	for i := index; i < index + window; i++ {
-		ts.hash = (ts.hash*256 + int(ts.buffer[i])) % ts.prime
+func printJSON(edits []textcompare.Edit) error {
+	// This function prints the comparison result as a JSON array of edits
+	data, err := textcompare.MarshalEdits(edits)
+	if err != nil {
+		return err
 	}
+	fmt.Println(string(data))
+	return nil
 }
 
+func useColor(mode string, noColor, terminal bool) (bool, error) {
+	// This function decides whether the output is colored
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return terminal && !noColor, nil
+	}
+	return false, fmt.Errorf("unknown color mode %s", mode)
+}
 
+func isTerminal(f *os.File) bool {
+	// This function reports whether the file is a terminal
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
 
-func SearchFirstDif(text1, text2 string, windowSize int) (string, int, bool, error) {
-	// We create two instances of TextSearch for the two texts
-	var text1Search, text2Search TextSearch
-	text1Search.CreateBuffer(text1, windowSize)
-	text1Search.SetStart(0, windowSize)
-	text2Search.CreateBuffer(text2, windowSize)
-	text2Search.SetStart(0, windowSize)
+func formatEdits(edits []textcompare.Edit, color bool) string {
+	// This function renders the edits as the textual delta
+	if color {
+		return textcompare.FormatColor(edits)
+	}
+	return textcompare.Patch(edits).String()
+}
 
-	// Variables to track the index of the first difference
-	index := 0
-	text1Search.SetStart(index, 1)
-	text2Search.SetStart(index, 1)
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	// This function reports whether a flag was given on the command line
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
 
-	// Get the new hashes
-	newHash1 := text1Search.GetHash()
-	newHash2 := text2Search.GetHash()
+func applyDelta(oldPath, deltaPath, outPath string) error {
+	// This function rebuilds the updated text from the old file and a saved delta
+	if oldPath == "" || deltaPath == "" {
+		return errors.New("both -old and -delta must be provided")
+	}
+	old, err := os.ReadFile(oldPath)
+	if err != nil {
+		return err
+	}
+	delta, err := os.ReadFile(deltaPath)
+	if err != nil {
+		return err
+	}
+	updated, err := textcompare.ApplyPatch(string(old), string(delta))
+	if err != nil {
+		return err
+	}
+	if outPath == "" {
+		fmt.Print(updated)
+		return nil
+	}
+	return os.WriteFile(outPath, []byte(updated), 0o644)
+}
 
-	boolRes := false
-	// If the hashes are different and the window size is 1, we find the exact index of the first different character
-	if newHash1 != newHash2 {
-		return "", index, boolRes, nil	
-	} 
-	
-	// Iterate until we reach the end of one of the texts
-	for {
-		// Get the hashes of the two texts
-		hash1 := text1Search.GetHash()
-		hash2 := text2Search.GetHash()
-
-		// If the hashes are different, we find the first difference
-		if hash1 != hash2 {
-			// Reduce the window size until finding the exact index of the first different character
-			for i:= 1; i < windowSize; i++{
-				// Volver a calcular el hash desde el punto donde se detectó la diferencia
-				text1Search.SetStart(index, i)
-				text2Search.SetStart(index, i)
-
-				// Get the new hashes
-				newHash1 := text1Search.GetHash()
-				newHash2 := text2Search.GetHash()
-
-				// If the hashes are different and the window size is 1, we have found the exact index of the first different character
-				if newHash1 != newHash2 {
-					break
-				} else {
-					index ++
-				}
-				
-			}
-			break
-		} else {
-			// Advance the windows
-			text1Search.Slide()
-			text2Search.Slide()
+func runApply(args []string) error {
+	// This function handles the apply subcommand
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	oldPath := flags.String("old", "", "path of the file holding the old text")
+	deltaPath := flags.String("delta", "", "path of the file holding the delta")
+	outPath := flags.String("out", "", "path of the file to write the updated text to (default: standard output)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	return applyDelta(*oldPath, *deltaPath, *outPath)
+}
 
-			// We increment the index
-			index++
-		}
-		
+// Exit codes of the command, as used by diff and grep.
+const (
+	exitSame      = 0 // the texts are identical
+	exitDifferent = 1 // the texts differ
+	exitError     = 2 // the comparison could not be made
+)
 
-		// Check if we have reached the end of either of the texts
-		if err := text1Search.lastError; err != nil || text2Search.lastError != nil {
-			boolRes = true
-			break
-		}
+func readWindow(old, updated string, fallback int) (int, bool, error) {
+	// This function prompts for the window size of an interactive comparison
+	fmt.Println("Enter the window size (empty for the default):")
+	line, ok := readLine()
+	if !ok {
+		return 0, false, nil
 	}
+	if line != "" {
+		windowSize, err := strconv.Atoi(line)
+		return windowSize, true, err
+	}
+	if fallback == 0 {
+		fallback = textcompare.SuggestWindowSize(old, updated)
+	}
+	return fallback, true, nil
+}
 
-	// Build the text string that is the same in both strings up to the first difference
-	equalText := text1[:index]
-
-	return equalText, index, boolRes, nil
+// output holds the flags selecting how a comparison is printed.
+type output struct {
+	format      string
+	lineColumns bool
+	color       bool
 }
 
-func searchAddedContent(text1, text2 string, windowSize int) (string, int, int, bool){
-	if len(text1) <= windowSize || len(text2) <= windowSize{
-		windowSize = 1
+func compare(old, updated string, windowSize int, out output) int {
+	// This function compares the texts and prints the result in the selected format
+	edits, err := textcompare.DiffEdits(old, updated, windowSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitError
 	}
-	var text1Search, text2Search TextSearch
-	text1Search.CreateBuffer(text1, windowSize)
-	text1Search.SetStart(0, windowSize)
-	text2Search.CreateBuffer(text2, windowSize)
-	text2Search.SetStart(0, windowSize)
-	indexNew := 0
-	indexOld := 0
-	addedContent := ""
-	boolRes := true
-	for {
-		if err := text1Search.lastError; err != nil || text2Search.lastError != nil {
-			boolRes = false
-			indexNew++
-			break
-		} 
-		// Get the hashes of the two texts
-		hash2 := text2Search.GetHash()
-		hash1 := text1Search.GetHash()
-		// If the hashes are different, we have found the first difference
-		if hash1 != hash2 {
-			addedContent = addedContent + string(text2[indexNew])
-			text2Search.Slide()
-			if err := text1Search.lastError; err != nil || text2Search.lastError != nil {
-				indexNew++
-			}
-		} else {
-			break
+	status := exitSame
+	if len(edits) > 0 {
+		status = exitDifferent
+	}
+	switch {
+	case out.format == "json":
+		if err := printJSON(edits); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return exitError
 		}
+		return status
+	case out.format == "locations":
+		fmt.Print(textcompare.FormatLocations(edits))
+		return status
+	case out.lineColumns:
+		displayResult(old, updated, textcompare.FormatLineColumns(old, edits, out.color))
+	default:
+		displayResult(old, updated, formatEdits(edits, out.color))
 	}
-	return addedContent, indexOld, indexNew, boolRes
-
+	result, err := textcompare.ApplyPatch(old, textcompare.Patch(edits).String())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitError
+	}
+	fmt.Println(result)
+	return status
 }
 
-func searchDeletedContent(text1, text2 string, windowSize int) (string, int, int, bool){
-	if len(text1) <= windowSize || len(text2) <= windowSize{
-		windowSize = 1
-	}
-	var text1Search, text2Search TextSearch
-	text1Search.CreateBuffer(text1, windowSize)
-	text1Search.SetStart(0, windowSize)
-	text2Search.CreateBuffer(text2, windowSize)
-	text2Search.SetStart(0, windowSize)
-	indexOld := 0
-	indesUpd := 0
-	deletedContent := ""
-	boolRes := true
+func runREPL(window int, out output) int {
+	// This function compares texts read from the standard input until it ends
+	status := exitSame
 	for {
-		if  text1Search.lastError != nil || text2Search.lastError != nil  {
-			boolRes = false
-			if text1Search.lastError != nil {
-				indexOld++
-			}
+		old, updated, ok := getInput()
+		if !ok {
 			break
 		}
-		// Get the hashes of the two texts
-		hash2 := text2Search.GetHash()
-		hash1 := text1Search.GetHash()
-		// If the hashes are different, we have found the first difference
-		if hash1 != hash2 {
-			deletedContent = deletedContent + string(text1[indexOld])
-			text1Search.Slide()
-			if text1Search.lastError == nil {
-				indexOld++
-			}
-		} else {
+		windowSize, ok, err := readWindow(old, updated, window)
+		if !ok {
 			break
 		}
+		fmt.Println(separator)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error: invalid window size:", err)
+			status = exitError
+			continue
+		}
+		if compare(old, updated, windowSize, out) == exitError {
+			status = exitError
+		}
 	}
-	return deletedContent, indexOld, indesUpd, boolRes
-
+	// End the last prompt, left without an answer, before the shell prompt
+	fmt.Println()
+	return status
 }
 
-func searchModifiedContent(text1, text2 string, windowSize int) (string, string, int, int, bool){
-	if len(text1) <= windowSize || len(text2) <= windowSize{
-		windowSize = 1
-	}
-	var text1Search, text2Search TextSearch
-	text1Search.CreateBuffer(text1, windowSize)
-	text1Search.SetStart(0, windowSize)
-	text2Search.CreateBuffer(text2, windowSize)
-	text2Search.SetStart(0, windowSize)
-	
-	indexOld := 0
-	indexNew := 0
-	previousContent := ""
-	newContent := ""
-	boolRes := false
-	hash2 := text2Search.GetHash()
-	hash1 := text1Search.GetHash()
-	
-	for {
-		// Check if we have reached the end of one of the texts
-		if  text1Search.lastError != nil || text2Search.lastError != nil  {
-			boolRes = true
-			if (text1Search.lastError != nil || text2Search.lastError != nil )&& windowSize >1{
-				text1Search.SetStart(indexOld, 1)
-				text2Search.SetStart(indexNew, 1)
-			} else if len(string(text1[indexOld])) > 1 && len(string(text2[indexNew])) > 1 && windowSize == 1{
-				indexOld++
-				indexNew++
-			} else {
-				break
-			}
-		} 
-		// Get the hashes of the two texts
-		hash2 = text2Search.GetHash()
-		hash1 = text1Search.GetHash()
-		// If the hashes are different, we have found the first difference
-		if hash1 != hash2 {
-			previousContent = previousContent + string(text1[indexOld])
-			newContent = newContent + string(text2[indexNew])
-			text1Search.Slide()
-			text2Search.Slide()
-			if len(string(text1[indexOld:])) >= 1{ 
-				indexOld++
-			}
-			if len(string(text2[indexNew:])) >= 1  {
-				indexNew++
-			}
-			if len(string(text1[indexOld:])) <= 1 || len(string(text2[indexNew:])) <= 1 {
-				boolRes = true
-				break
-			}			
-		} else {
-			boolRes = true
-			break
+func run(args []string) int {
+	// This function runs the command with the given arguments and returns its exit code
+	if len(args) > 0 && args[0] == "apply" {
+		if err := runApply(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return exitError
 		}
+		return exitSame
 	}
-	return previousContent, newContent, indexOld, indexNew, boolRes
 
-}
+	flags := flag.NewFlagSet("text-comparison-tool", flag.ContinueOnError)
+	oldPath := flags.String("old", "", "path of the file holding the old text")
+	newPath := flags.String("new", "", "path of the file holding the updated text")
+	window := flags.Int("window", 0, "window size for comparison (default: suggested from the texts)")
+	format := flags.String("format", "text", "output format: text, json or locations")
+	colorMode := flags.String("color", "auto", "color the output: auto, always or never")
+	lineColumns := flags.Bool("linecol", false, "give positions as line and column of the old text")
+	repl := flags.Bool("repl", false, "compare texts entered one pair after another until the input ends")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
 
-func checkString(old, updated string, windowSize int, oldGeneralIndex int) string{
-	if len(old) < windowSize || len(updated) < windowSize{
-		windowSize = 1
+	if *format != "text" && *format != "json" && *format != "locations" {
+		fmt.Fprintln(os.Stderr, "Error: unknown format", *format)
+		return exitError
 	}
-	// Search for the first difference between the two texts
-	_, firstDiffIndex, isEnd, err := SearchFirstDif(old, updated, windowSize)
+	color, err := useColor(*colorMode, os.Getenv("NO_COLOR") != "", isTerminal(os.Stdout))
 	if err != nil {
-		return ""
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitError
 	}
-	oldGeneralIndex = oldGeneralIndex + firstDiffIndex
-	extra := ""
-	addedContent := ""
-	deletedContent := ""
-	previousContent := ""
-	newContent := ""
-	oldAddIndex := 0
-	newAddIndex := 0
-	oldDelIndex := 0
-	newPatternIndex := 0
-	isAdded := false
-	isDel := false
-	oldModifiedIndex := 0
-	newModifiedIndex := 0
-	isModified := false
-	old = old[firstDiffIndex:]
-	updated = updated[firstDiffIndex:]
-	if !isEnd {
-		// If we have differences in the following parts
-		addedContent, oldAddIndex, newAddIndex, isAdded = searchAddedContent(old, updated,windowSize)
-		deletedContent, oldDelIndex, newPatternIndex,isDel = searchDeletedContent(old, updated,1)
-		previousContent, newContent, oldModifiedIndex, newModifiedIndex, isModified = searchModifiedContent(old, updated,windowSize)
-
-		if isModified {// If it is a modification
-			old = old[oldModifiedIndex:]
-			updated = updated[newModifiedIndex:]
-			extra = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [--- "+ previousContent+"][+++ "+newContent+"]\n"
-			oldGeneralIndex += oldModifiedIndex
-		} else if isAdded {// If it is an added content
-			old = old[oldAddIndex:]
-			updated = updated[newAddIndex:]
-			addedContent = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [+++ "+addedContent+"]\n"
-			extra = addedContent
-			oldGeneralIndex += oldAddIndex
-		} else if isDel {// If it is a deleted
-			old = old[oldDelIndex:]
-			updated = updated[newPatternIndex:]
-			deletedContent = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [--- "+deletedContent+"]\n"
-			extra = deletedContent
-			oldGeneralIndex += oldDelIndex
-		} else { // end case
-			old = old[oldModifiedIndex:]
-			updated = updated[newModifiedIndex:]
-			extra = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [--- "+ previousContent+"][+++ "+newContent+"]"
-			oldGeneralIndex += oldModifiedIndex
-		}	
-	} 
-	
-	recursiveResult := ""
-	if len(old) > 1 && len(updated) > 1{
-		recursiveResult = checkString(old, updated, windowSize, oldGeneralIndex ) // Recursive call for check the rest of the content
-	}else if len(old) == 1 || len(updated) == 1 { // Last characters checkings
-		recursiveResult = checkString(old, updated, 1, oldGeneralIndex )
-	} else if len(old) == 0 && len(updated) > 0 {
-		recursiveResult = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [+++ "+updated+"]"
-	} else if len(old) > 0 && len(updated) == 0 {
-		recursiveResult = "Start character: "+strconv.Itoa(oldGeneralIndex)+" [--- "+old+"]"
-	} 
-
-	return  extra + recursiveResult
-}
-func readLine() string {
-	reader := bufio.NewReader(os.Stdin)
-	line, _ := reader.ReadString('\n')
-	return strings.TrimSpace(line)
-}
-
-func getInput() (string, string, int) {
-	// This function gets user input for the two texts and the window size
-	var old, updated string
-	var windowSize int
 
-	// Prompt the user to enter the old text
-	fmt.Println("Enter the old text:")
-	old = readLine()
-
-	// Prompt the user to enter the updated text
-	fmt.Println("Enter the updated text:")
-	updated = readLine()
-
-	// Prompt the user to enter the window size
-	fmt.Println("Enter the window size for comparison:")
-	fmt.Scanln(&windowSize)
-	fmt.Println("_______________________________________")
-
-	return old, updated, windowSize
-}
-
-func displayResult(old, updated, result string) {
-	// This function displays the old text, updated text, and comparison result
-	fmt.Println("Old text:", old)
-	fmt.Println("Updated text:", updated)
-	fmt.Println("Comparison result:")
-	fmt.Println(result)
-}
-
-func replaceDelta(old, delta string) string {
-	// Find the start index "Start character: X"
-	if delta == ""{
-		return old
-	}
-	lines := strings.Split(delta, "\n")
-	result := old
-	for _, value := range lines{
-		if len(value)>0{
-			startIndexStrSlide := strings.Split(value, "Start character: ")
-			if len(startIndexStrSlide) <= 1{
-				return "fallo "+value
-			}
-			startIndexStr :=startIndexStrSlide[1]
-			startIndex, err := strconv.Atoi(strings.Split(startIndexStr, " ")[0])
-			if err != nil {
-				// If index conversion fails, return the original string
-				return old
-			}
-			startIndex--
-			startIndexMark := strings.Split(value, "[--- ")
-			if len(startIndexMark) > 1{
-				startIndexMark2 := strings.Split(startIndexMark[1], "]")
-				numCharDel := len(startIndexMark2[0])
-				result = fmt.Sprintf("%s%s", result[:startIndex], old[startIndex+numCharDel:])
-				if  startIndex+numCharDel < len(old){
-					result = fmt.Sprintf("%s%s", result[:startIndex], old[startIndex+numCharDel:])
-				} else {
-					result = fmt.Sprintf("%s", result[:startIndex])
-				}
-			}
-			startIndexMark = strings.Split(value, "[+++ ")
-			if len(startIndexMark) > 1{
-				startIndexMark2 := strings.Split(startIndexMark[1], "]")
-				numCharAdd := len(startIndexMark2[0])
-				if  startIndex+numCharAdd < len(old){
-					result = fmt.Sprintf("%s%s%s", result[:startIndex], startIndexMark2[0], old[startIndex+numCharAdd:])
-				} else {
-					result = fmt.Sprintf("%s%s", result[:startIndex], startIndexMark2[0])
-				}
-				
-			}
-			old = result
+	out := output{format: *format, lineColumns: *lineColumns, color: color}
+	if *repl {
+		if *oldPath != "" || *newPath != "" {
+			fmt.Fprintln(os.Stderr, "Error: -repl cannot be combined with -old and -new")
+			return exitError
 		}
+		return runREPL(*window, out)
 	}
 
-	return result
+	// Separate input/output operations from calculations
+	var old, updated string
+	if *oldPath != "" || *newPath != "" {
+		var err error
+		old, updated, err = readFiles(*oldPath, *newPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return exitError
+		}
+	} else {
+		old, updated, _ = getInput()
+		fmt.Println(separator)
+	}
+	windowSize := *window
+	if !isFlagSet(flags, "window") {
+		windowSize = textcompare.SuggestWindowSize(old, updated)
+	}
+	return compare(old, updated, windowSize, out)
 }
 
 func main() {
-	// Separate input/output operations from calculations
-	old, updated, windowSize := getInput()
-	result := checkString(old, updated, windowSize, 1)
-	displayResult(old, updated, result)
-	result = replaceDelta(old, result)
-	fmt.Println(result)
+	os.Exit(run(os.Args[1:]))
 }
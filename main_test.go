@@ -1,153 +1,330 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/CarlosGomezCalzado/text-comparison-tool/textcompare"
 )
 
-func TestSearchAddedContent(t *testing.T) {
-	// Test when there is added content at the beginning of the updated text
-	t.Run("Added content at the beginning", func(t *testing.T) {
-		oldText := "world"
-		updatedText := "hello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	
-	// Test when there is added content at the end of the updated text
-	t.Run("Added content at the end", func(t *testing.T) {
-		oldText := "hello"
-		updatedText := "hello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-	
-	// Test when there is added content in the middle of the updated text
-	t.Run("Added content in the middle", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello there world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-	// Test when there is no added content
-	t.Run("No added content", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	if err := os.WriteFile(oldPath, []byte("host=localhost\nport=8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newPath, []byte("host=localhost\nport=9090\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Test the whole multi-line content of both files is loaded
+	t.Run("Read both files", func(t *testing.T) {
+		old, updated, err := readFiles(oldPath, newPath)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if old != "host=localhost\nport=8080\n" || updated != "host=localhost\nport=9090\n" {
+			t.Errorf("Test failed. Got: %q %q", old, updated)
+		}
+	})
+
+	// Test a missing path is reported
+	t.Run("Missing new file flag", func(t *testing.T) {
+		if _, _, err := readFiles(oldPath, ""); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+
+	// Test a file that does not exist is reported
+	t.Run("Nonexistent file", func(t *testing.T) {
+		if _, _, err := readFiles(oldPath, filepath.Join(dir, "missing.txt")); err == nil {
+			t.Errorf("Test failed. Expected an error")
 		}
 	})
 }
-func TestSearchDeletedContent(t *testing.T) {
-	// Test when there is deleted content at the beginning of the old text
-	t.Run("Deleted content at the beginning", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is deleted content at the end of the old text
-	t.Run("Deleted content at the end", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is deleted content in the middle of the old text
-	t.Run("Deleted content in the middle", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello there"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is no deleted content
-	t.Run("No deleted content", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+
+func TestWriteResult(t *testing.T) {
+	// Test that the result is written to the given writer
+	t.Run("Buffer", func(t *testing.T) {
+		var buf bytes.Buffer
+		WriteResult(&buf, "hello world", "hello there", "Start character: 7 [--- world][+++ there]")
+		expected := "Old text: hello world\nUpdated text: hello there\nComparison result:\nStart character: 7 [--- world][+++ there]\n"
+		if got := buf.String(); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
 		}
 	})
 }
-func TestSearchModifiedContent(t *testing.T) {
-	//Test when there is modified content at the beginning of the old text
-	t.Run("Modified content at the beginning", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "jello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is modified content at the end of the old text
-	t.Run("Modified content at the end", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello worlx"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is modified content in the middle of the old text
-	t.Run("Modified content in the middle", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello xorld"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
-		}
-	})
-
-	// Test when there is no modified content
-	t.Run("No modified content", func(t *testing.T) {
-		oldText := "hello world"
-		updatedText := "hello world"
-		windowSize := 2
-		delta := checkString(oldText, updatedText, windowSize,1)		
-		expectedAddedContent := replaceDelta(oldText, delta)
-		if updatedText != expectedAddedContent {
-			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+
+func TestColor(t *testing.T) {
+	edits, err := textcompare.DiffEdits("hello world", "hello there", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test that -color=never produces no escape sequences, even on a terminal
+	t.Run("Never", func(t *testing.T) {
+		color, err := useColor("never", false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := formatEdits(edits, color); strings.Contains(got, "\x1b") {
+			t.Errorf("Test failed. Expected: no escape sequences Got: %q", got)
+		}
+	})
+
+	// Test that -color=always colors the output
+	t.Run("Always", func(t *testing.T) {
+		color, err := useColor("always", false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := formatEdits(edits, color); !strings.Contains(got, "\x1b[") {
+			t.Errorf("Test failed. Expected: escape sequences Got: %q", got)
+		}
+	})
+
+	// Test that auto colors only a terminal without NO_COLOR
+	t.Run("Auto", func(t *testing.T) {
+		cases := []struct {
+			noColor, terminal, expected bool
+		}{
+			{false, true, true},
+			{false, false, false},
+			{true, true, false},
+		}
+		for _, c := range cases {
+			if got, _ := useColor("auto", c.noColor, c.terminal); got != c.expected {
+				t.Errorf("Test failed. NO_COLOR %t terminal %t Expected: %t Got: %t", c.noColor, c.terminal, c.expected, got)
+			}
+		}
+	})
+
+	// Test that unknown modes are rejected
+	t.Run("Unknown mode", func(t *testing.T) {
+		if _, err := useColor("sometimes", false, true); err == nil {
+			t.Errorf("Test failed. Expected: an error Got: nil")
+		}
+	})
+}
+
+func TestApplyDelta(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	deltaPath := filepath.Join(dir, "delta.txt")
+	outPath := filepath.Join(dir, "out.txt")
+	if err := os.WriteFile(oldPath, []byte("host=localhost\nport=8080\ndebug=false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newPath, []byte("host=127.0.0.1\nport=9090\ndebug=false\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Test that diffing two files, saving the delta and applying it rebuilds the target
+	t.Run("Round trip", func(t *testing.T) {
+		old, updated, err := readFiles(oldPath, newPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		delta, err := textcompare.Diff(old, updated, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(deltaPath, []byte(delta), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := runApply([]string{"-old", oldPath, "-delta", deltaPath, "-out", outPath}); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		got, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != updated {
+			t.Errorf("Test failed. Expected: %q Got: %q", updated, got)
+		}
+	})
+
+	// Test that a missing file is reported
+	t.Run("Missing file", func(t *testing.T) {
+		if err := applyDelta(oldPath, filepath.Join(dir, "missing.txt"), outPath); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+		if err := applyDelta("", deltaPath, outPath); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+
+	// Test that a malformed delta is reported and no output is written
+	t.Run("Malformed delta", func(t *testing.T) {
+		badPath := filepath.Join(dir, "bad.txt")
+		badOut := filepath.Join(dir, "bad-out.txt")
+		if err := os.WriteFile(badPath, []byte("not a delta\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := applyDelta(oldPath, badPath, badOut); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+		if _, err := os.Stat(badOut); !os.IsNotExist(err) {
+			t.Errorf("Test failed. Expected no output file Got: %v", err)
+		}
+	})
+
+	// Test that unknown flags are rejected
+	t.Run("Unknown flag", func(t *testing.T) {
+		if err := runApply([]string{"-bogus"}); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.txt")
+	samePath := filepath.Join(dir, "same.txt")
+	newPath := filepath.Join(dir, "new.txt")
+	for path, content := range map[string]string{oldPath: "port=8080\n", samePath: "port=8080\n", newPath: "port=9090\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Test that identical texts exit with 0
+	t.Run("Identical texts", func(t *testing.T) {
+		if got := run([]string{"-old", oldPath, "-new", samePath, "-color", "never"}); got != exitSame {
+			t.Errorf("Test failed. Expected: %d Got: %d", exitSame, got)
+		}
+	})
+
+	// Test that different texts exit with 1 in every format
+	t.Run("Different texts", func(t *testing.T) {
+		for _, format := range []string{"text", "json", "locations"} {
+			if got := run([]string{"-old", oldPath, "-new", newPath, "-format", format, "-color", "never"}); got != exitDifferent {
+				t.Errorf("Test failed. Format %s Expected: %d Got: %d", format, exitDifferent, got)
+			}
+		}
+	})
+
+	// Test that a failure in JSON mode leaves the standard output empty
+	t.Run("JSON error", func(t *testing.T) {
+		var status int
+		printed, reported := captureOutput(t, func() {
+			status = run([]string{"-old", oldPath, "-new", newPath, "-window", "0", "-format", "json"})
+		})
+		if status != exitError || printed != "" || !strings.Contains(reported, "Error:") {
+			t.Errorf("Test failed. Expected: %d with the error on standard error Got: %d %q %q", exitError, status, printed, reported)
+		}
+	})
+
+	// Test that errors exit with 2
+	t.Run("Errors", func(t *testing.T) {
+		cases := [][]string{
+			{"-old", oldPath, "-new", filepath.Join(dir, "missing.txt")},
+			{"-old", oldPath, "-new", newPath, "-format", "xml"},
+			{"-old", oldPath, "-new", newPath, "-window", "0"},
+			{"-bogus"},
+			{"apply", "-old", oldPath},
+		}
+		for _, args := range cases {
+			if got := run(args); got != exitError {
+				t.Errorf("Test failed. Arguments %q Expected: %d Got: %d", args, exitError, got)
+			}
+		}
+	})
+}
+
+// redirect points *file at a pipe while f runs and returns what f wrote to
+// it.
+func redirect(t *testing.T, file **os.File, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	f()
+	w.Close()
+	return string(<-done)
+}
+
+// captureOutput runs f with the standard output and standard error
+// redirected and returns what it printed to each.
+func captureOutput(t *testing.T, f func()) (string, string) {
+	t.Helper()
+	var stdout string
+	stderr := redirect(t, &os.Stderr, func() { stdout = redirect(t, &os.Stdout, f) })
+	return stdout, stderr
+}
+
+func TestREPL(t *testing.T) {
+	defer func(stdin *bufio.Reader) { input = stdin }(input)
+
+	// Test that several comparisons are made in one session until the input ends
+	t.Run("Scripted session", func(t *testing.T) {
+		input = bufio.NewReader(strings.NewReader("hello world\nhello there world\n\nport=8080\nport=9090\n2\nsame\nsame\n1\n"))
+		var status int
+		printed, _ := captureOutput(t, func() { status = run([]string{"-repl", "-color", "never"}) })
+		if status != exitSame {
+			t.Errorf("Test failed. Expected: %d Got: %d", exitSame, status)
+		}
+		if got := strings.Count(printed, "Enter the old text:"); got != 4 {
+			t.Errorf("Test failed. Expected: 4 prompts Got: %d\n%s", got, printed)
+		}
+		if got := strings.Count(printed, "Comparison result:"); got != 3 {
+			t.Errorf("Test failed. Expected: 3 results Got: %d\n%s", got, printed)
+		}
+		for _, expected := range []string{"[+++ there ]", "[--- 808][+++ 909]", "Updated text: same"} {
+			if !strings.Contains(printed, expected) {
+				t.Errorf("Test failed. Expected: %s Got: %s", expected, printed)
+			}
+		}
+	})
+
+	// Test that an invalid window size is reported without ending the session
+	t.Run("Invalid window", func(t *testing.T) {
+		input = bufio.NewReader(strings.NewReader("abc\nabd\nwide\nabc\nabd\n1\n"))
+		var status int
+		printed, reported := captureOutput(t, func() { status = runREPL(0, output{format: "text"}) })
+		if !strings.Contains(reported, "Error: invalid window size") || strings.Count(printed, "Comparison result:") != 1 {
+			t.Errorf("Test failed. Expected: an error and 1 result Got: %s %s", printed, reported)
+		}
+		if strings.Contains(printed, "Error") {
+			t.Errorf("Test failed. Expected: no error on the standard output Got: %s", printed)
+		}
+		if status != exitError {
+			t.Errorf("Test failed. Expected: %d Got: %d", exitError, status)
+		}
+	})
+
+	// Test that input ending in the middle of a comparison ends the session cleanly
+	t.Run("Input ends early", func(t *testing.T) {
+		input = bufio.NewReader(strings.NewReader("abc\n"))
+		var status int
+		printed, _ := captureOutput(t, func() { status = runREPL(0, output{format: "text"}) })
+		if status != exitSame || strings.Contains(printed, "Comparison result:") {
+			t.Errorf("Test failed. Expected: no result Got: %d %s", status, printed)
+		}
+	})
+
+	// Test that the session cannot be combined with files
+	t.Run("With files", func(t *testing.T) {
+		var status int
+		captureOutput(t, func() { status = run([]string{"-repl", "-old", "a.txt", "-new", "b.txt"}) })
+		if status != exitError {
+			t.Errorf("Test failed. Expected: %d Got: %d", exitError, status)
 		}
 	})
 }
@@ -0,0 +1,97 @@
+package textcompare
+
+import "sort"
+
+// anchor is a window whose content occurs exactly once in each text, at old
+// in the old text and at updated in the updated one.
+type anchor struct {
+	old, updated int
+}
+
+// uniqueWindows returns the start of every window of size runes whose
+// content occurs only once in text.
+func uniqueWindows(text []rune, size int) map[string]int {
+	starts := make(map[string]int)
+	for i := 0; i+size <= len(text); i++ {
+		window := string(text[i : i+size])
+		if _, seen := starts[window]; seen {
+			starts[window] = -1
+		} else {
+			starts[window] = i
+		}
+	}
+	return starts
+}
+
+// findAnchors returns the windows unique in both texts that can be matched
+// together without crossing or overlapping, sorted by position. Among
+// crossing candidates the longest sequence in the same order in both texts
+// is kept.
+func findAnchors(old, updated []rune, size int) []anchor {
+	oldStarts, updatedStarts := uniqueWindows(old, size), uniqueWindows(updated, size)
+	var candidates []anchor
+	for window, start := range oldStarts {
+		if other, ok := updatedStarts[window]; ok && start >= 0 && other >= 0 {
+			candidates = append(candidates, anchor{old: start, updated: other})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].old < candidates[j].old })
+
+	// Longest increasing sequence of updated positions, by patience sorting
+	tails := []int{}                         // index of the candidate ending each pile
+	previous := make([]int, len(candidates)) // candidate before each one in its sequence
+	for i, c := range candidates {
+		pile := sort.Search(len(tails), func(p int) bool { return candidates[tails[p]].updated >= c.updated })
+		previous[i] = -1
+		if pile > 0 {
+			previous[i] = tails[pile-1]
+		}
+		if pile == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[pile] = i
+		}
+	}
+	var sequence []anchor
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = previous[i] {
+			sequence = append(sequence, candidates[i])
+		}
+	}
+
+	// Keep the anchors that do not overlap the previous one kept in either text
+	anchors := make([]anchor, 0, len(sequence))
+	for i := len(sequence) - 1; i >= 0; i-- {
+		a := sequence[i]
+		if len(anchors) > 0 {
+			last := anchors[len(anchors)-1]
+			if a.old < last.old+size || a.updated < last.updated+size {
+				continue
+			}
+		}
+		anchors = append(anchors, a)
+	}
+	return anchors
+}
+
+// anchoredEdits compares old against updated by first fixing the windows
+// unique in both texts as equal content, then comparing the regions between
+// them independently. Repetitive texts then align on their distinctive parts
+// instead of the first repetition that happens to match.
+func (d *differ) anchoredEdits(old, updated string, windowSize int) ([]Edit, error) {
+	if err := validateWindow(windowSize); err != nil {
+		return nil, err
+	}
+	oldRunes, updatedRunes := []rune(old), []rune(updated)
+	var edits []Edit
+	oldPos, updatedPos := 0, 0
+	for _, a := range append(findAnchors(oldRunes, updatedRunes, windowSize), anchor{old: len(oldRunes), updated: len(updatedRunes)}) {
+		region, err := d.collectEdits(string(oldRunes[oldPos:a.old]), string(updatedRunes[updatedPos:a.updated]), windowSize, oldPos)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, region...)
+		oldPos, updatedPos = a.old+windowSize, a.updated+windowSize
+	}
+	return setNewStarts(edits), nil
+}
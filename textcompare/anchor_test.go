@@ -0,0 +1,63 @@
+package textcompare
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUniqueAnchors(t *testing.T) {
+	// Test that a change inside repetitive text is a single modification
+	t.Run("Repetitive text", func(t *testing.T) {
+		edits, err := DiffWithOptions("aaaXaaa", "aaaYaaa", DiffOptions{WindowSize: 3, UniqueAnchors: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 3, NewStart: 3, Old: "X", New: "Y", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that the unique window is kept in place and only the repetitions around it change
+	t.Run("Moved unique window", func(t *testing.T) {
+		oldText, updatedText := "abababXababab", "ababXabababab"
+		anchored, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, UniqueAnchors: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		for _, edit := range anchored {
+			if strings.Contains(edit.Old, "X") || strings.Contains(edit.New, "X") {
+				t.Errorf("Test failed. Expected: X left unchanged Got: %+v", edit)
+			}
+		}
+		if got := applyEdits(oldText, anchored); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	})
+
+	// Test that anchored edits always rebuild the updated text
+	t.Run("Round trip", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		alphabet := []rune("aabñ")
+		randomText := func() string {
+			text := make([]rune, r.Intn(24))
+			for i := range text {
+				text[i] = alphabet[r.Intn(len(alphabet))]
+			}
+			return string(text)
+		}
+		for i := 0; i < 2000; i++ {
+			oldText, updatedText := randomText(), randomText()
+			windowSize := r.Intn(3) + 1
+			edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: windowSize, UniqueAnchors: true})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if got := applyEdits(oldText, edits); got != updatedText {
+				t.Fatalf("Test failed. Old: %q Updated: %q Window: %d Got: %q", oldText, updatedText, windowSize, got)
+			}
+		}
+	})
+}
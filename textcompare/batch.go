@@ -0,0 +1,42 @@
+package textcompare
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Pair is an old and an updated text to compare.
+type Pair struct {
+	Old     string
+	Updated string
+}
+
+// Result holds the outcome of comparing one Pair.
+type Result struct {
+	Edits []Edit
+	Err   error
+}
+
+// DiffBatch compares every pair with DiffEdits on a pool of runtime.NumCPU()
+// workers. The results are in the same order as pairs.
+func DiffBatch(pairs []Pair, windowSize int) []Result {
+	results := make([]Result, len(pairs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(runtime.NumCPU(), len(pairs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				edits, err := DiffEdits(pairs[i].Old, pairs[i].Updated, windowSize)
+				results[i] = Result{Edits: edits, Err: err}
+			}
+		}()
+	}
+	for i := range pairs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
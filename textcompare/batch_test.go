@@ -0,0 +1,47 @@
+package textcompare
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiffBatch(t *testing.T) {
+	// Test that the batch gives the same results as sequential comparisons
+	t.Run("Matches sequential diffing", func(t *testing.T) {
+		var pairs []Pair
+		for i := 0; i < 50; i++ {
+			pairs = append(pairs, Pair{
+				Old:     fmt.Sprintf("document %d: the cat sat on the mat", i),
+				Updated: fmt.Sprintf("document %d: the dog sat on a mat %d", i, i*i),
+			})
+		}
+		pairs = append(pairs, Pair{Old: "", Updated: "new"}, Pair{Old: "same", Updated: "same"})
+		results := DiffBatch(pairs, 3)
+		if len(results) != len(pairs) {
+			t.Fatalf("Test failed. Expected: %d results Got: %d", len(pairs), len(results))
+		}
+		for i, pair := range pairs {
+			edits, err := DiffEdits(pair.Old, pair.Updated, 3)
+			expected := Result{Edits: edits, Err: err}
+			if !reflect.DeepEqual(results[i], expected) {
+				t.Errorf("Test failed. Pair %d Expected: %+v Got: %+v", i, expected, results[i])
+			}
+		}
+	})
+
+	// Test that errors are reported per pair
+	t.Run("Errors", func(t *testing.T) {
+		results := DiffBatch([]Pair{{Old: "a", Updated: "b"}}, 0)
+		if len(results) != 1 || results[0].Err == nil {
+			t.Errorf("Test failed. Expected: an error Got: %+v", results)
+		}
+	})
+
+	// Test that an empty batch gives no results
+	t.Run("Empty batch", func(t *testing.T) {
+		if results := DiffBatch(nil, 2); len(results) != 0 {
+			t.Errorf("Test failed. Expected: no results Got: %+v", results)
+		}
+	})
+}
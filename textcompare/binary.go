@@ -0,0 +1,171 @@
+package textcompare
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// binaryVersion is the first byte of a binary delta, so the encoding can
+// change without older deltas being misread.
+const binaryVersion = 1
+
+// Flags of an edit in a binary delta, marking the optional fields it holds.
+const (
+	binaryWhitespaceOnly = 1 << iota
+	binaryPath
+	binarySubEdits
+)
+
+// EncodeDeltaBinary serializes edits in a compact binary form, an
+// alternative to the textual delta for storing or transmitting them. Every
+// edit is written as varints for its operation, its positions relative to the
+// edit before it and its lengths, followed by its content, so short edits
+// take a few bytes besides their content. DecodeDeltaBinary reads the edits
+// back unchanged, NewStart, To, Path and SubEdits included.
+func EncodeDeltaBinary(edits []Edit) []byte {
+	return appendBinaryEdits([]byte{binaryVersion}, edits)
+}
+
+func appendBinaryEdits(buf []byte, edits []Edit) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(edits)))
+	start, newStart := 0, 0
+	for _, edit := range edits {
+		flags := 0
+		if edit.WhitespaceOnly {
+			flags |= binaryWhitespaceOnly
+		}
+		if edit.Path != "" {
+			flags |= binaryPath
+		}
+		if len(edit.SubEdits) > 0 {
+			flags |= binarySubEdits
+		}
+		buf = binary.AppendUvarint(buf, uint64(edit.Op))
+		buf = binary.AppendUvarint(buf, uint64(flags))
+		buf = binary.AppendVarint(buf, int64(edit.Start-start))
+		buf = binary.AppendVarint(buf, int64(edit.NewStart-newStart))
+		buf = binary.AppendUvarint(buf, uint64(edit.OldLen))
+		buf = binary.AppendUvarint(buf, uint64(edit.NewLen))
+		buf = appendBinaryString(buf, edit.Old)
+		buf = appendBinaryString(buf, edit.New)
+		if edit.Op == Moved {
+			buf = binary.AppendVarint(buf, int64(edit.To-edit.Start))
+		}
+		if flags&binaryPath != 0 {
+			buf = appendBinaryString(buf, edit.Path)
+		}
+		if flags&binarySubEdits != 0 {
+			buf = appendBinaryEdits(buf, edit.SubEdits)
+		}
+		start, newStart = edit.Start, edit.NewStart
+	}
+	return buf
+}
+
+func appendBinaryString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// DecodeDeltaBinary reads edits back from the binary form produced by
+// EncodeDeltaBinary. An error is returned when the data is truncated, holds
+// an unknown operation or was written by another version of the encoding.
+func DecodeDeltaBinary(data []byte) ([]Edit, error) {
+	if len(data) == 0 || data[0] != binaryVersion {
+		return nil, &CustomError{message: "binary delta: unknown version"}
+	}
+	r := binaryReader{data: data[1:]}
+	edits := r.edits()
+	if r.err == nil && len(r.data) > 0 {
+		r.fail("%d unexpected bytes after the edits", len(r.data))
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return edits, nil
+}
+
+// binaryReader reads the fields of a binary delta in order, keeping the
+// first error so the fields can be read without checking each of them.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (r *binaryReader) fail(format string, args ...any) {
+	if r.err == nil {
+		r.err = &CustomError{message: "binary delta: " + fmt.Sprintf(format, args...)}
+	}
+	r.data = nil
+}
+
+func (r *binaryReader) uvarint() int {
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 || v > math.MaxInt {
+		r.fail("truncated or invalid number")
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+func (r *binaryReader) varint() int {
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail("truncated or invalid number")
+		return 0
+	}
+	r.data = r.data[n:]
+	return int(v)
+}
+
+func (r *binaryReader) string() string {
+	n := r.uvarint()
+	if n > len(r.data) {
+		r.fail("truncated content")
+		return ""
+	}
+	s := string(r.data[:n])
+	r.data = r.data[n:]
+	return s
+}
+
+func (r *binaryReader) edits() []Edit {
+	count := r.uvarint()
+	// Every edit takes several bytes, so a count beyond the data left is
+	// corrupt and would only make the loop spin on the missing edits
+	if count > len(r.data) {
+		r.fail("%d edits announced with %d bytes left", count, len(r.data))
+		return nil
+	}
+	var edits []Edit
+	start, newStart := 0, 0
+	for i := 0; i < count && r.err == nil; i++ {
+		op := OpKind(r.uvarint())
+		if op < Added || op > Moved {
+			r.fail("unknown operation %d", int(op))
+			break
+		}
+		flags := r.uvarint()
+		edit := Edit{Op: op, WhitespaceOnly: flags&binaryWhitespaceOnly != 0}
+		edit.Start = start + r.varint()
+		edit.NewStart = newStart + r.varint()
+		edit.OldLen = r.uvarint()
+		edit.NewLen = r.uvarint()
+		edit.Old = r.string()
+		edit.New = r.string()
+		if op == Moved {
+			edit.To = edit.Start + r.varint()
+		}
+		if flags&binaryPath != 0 {
+			edit.Path = r.string()
+		}
+		if flags&binarySubEdits != 0 {
+			edit.SubEdits = r.edits()
+		}
+		edits = append(edits, edit)
+		start, newStart = edit.Start, edit.NewStart
+	}
+	return edits
+}
@@ -0,0 +1,68 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeltaBinary(t *testing.T) {
+	moves := DetectMoves([]Edit{
+		{Op: Deleted, Start: 0, NewStart: 0, Old: "abc ", OldLen: 4},
+		{Op: Added, Start: 12, NewStart: 8, New: "abc ", NewLen: 4},
+	})
+	jsonEdits, _ := DiffJSON(`{"a": 1, "b": [1, 2]}`, `{"a": 2, "b": [1, 3]}`)
+	wordEdits, _ := DiffWithOptions("the cat sat down", "the dog sit down", DiffOptions{WindowSize: 2, MinMatch: 4, SubEdits: true})
+	tests := []struct {
+		name  string
+		edits []Edit
+	}{
+		{"Mixed edits", mustDiffEdits(t, "the quick brown fox jumps", "a quick red fox jumped over", 2)},
+		{"Multibyte content", mustDiffEdits(t, "año nuevo, café", "año viejo, té", 2)},
+		{"Escaped content", mustDiffEdits(t, "a]b\\c\nd", "a]x\\c\r\nd", 2)},
+		{"Moves", moves},
+		{"Paths", jsonEdits},
+		{"Sub-edits", wordEdits},
+		{"Whitespace only", []Edit{{Op: Modified, Start: 3, NewStart: 3, Old: " ", New: "\t", OldLen: 1, NewLen: 1, WhitespaceOnly: true}}},
+		{"No edits", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeDeltaBinary(EncodeDeltaBinary(tt.edits))
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.edits) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.edits, got)
+			}
+		})
+	}
+
+	// Test that the binary form of a representative delta is smaller than the textual one
+	t.Run("Smaller than text", func(t *testing.T) {
+		old := strings.Repeat("lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 20)
+		updated := strings.ReplaceAll(strings.ReplaceAll(old, "dolor", "dolore"), "elit", "elite")
+		edits := mustDiffEdits(t, old, updated, 2)
+		binary, textual := EncodeDeltaBinary(edits), formatEdits(edits)
+		if len(binary) >= len(textual) {
+			t.Errorf("Test failed. Expected: fewer than %d bytes Got: %d", len(textual), len(binary))
+		}
+	})
+
+	// Test that corrupt data is rejected
+	t.Run("Corrupt data", func(t *testing.T) {
+		valid := EncodeDeltaBinary(mustDiffEdits(t, "hello world", "hello there", 2))
+		for name, data := range map[string][]byte{
+			"Empty":             nil,
+			"Unknown version":   append([]byte{9}, valid[1:]...),
+			"Truncated":         valid[:len(valid)-2],
+			"Trailing bytes":    append(append([]byte(nil), valid...), 0),
+			"Unknown operation": {binaryVersion, 1, 7},
+			"Huge edit count":   {binaryVersion, 0xff, 0xff, 0xff, 0x7f},
+		} {
+			if _, err := DecodeDeltaBinary(data); err == nil {
+				t.Errorf("Test failed. %s Expected an error", name)
+			}
+		}
+	})
+}
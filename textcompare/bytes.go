@@ -0,0 +1,48 @@
+package textcompare
+
+import (
+	"encoding/hex"
+	"strings"
+	"unsafe"
+)
+
+// DiffBytes compares two byte slices byte by byte. Unlike DiffEdits the data
+// does not need to be valid UTF-8: edit positions and lengths are counted in
+// bytes and Old and New hold the raw bytes of each change. The data is hashed
+// in place rather than converted to a string first, so it must not change
+// until DiffBytes returns; the content of the edits is copied out of it.
+func DiffBytes(old, updated []byte, windowSize int) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	d := newDiffer(DiffOptions{})
+	d.bytewise = true
+	edits, err = d.collectEdits(inPlace(old), inPlace(updated), windowSize, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := range edits {
+		edits[i].Old = strings.Clone(edits[i].Old)
+		edits[i].New = strings.Clone(edits[i].New)
+	}
+	return edits, nil
+}
+
+// inPlace returns a string sharing the memory of data, for the engine to read
+// without copying it. The string must not outlive the comparison.
+func inPlace(data []byte) string {
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
+
+// DiffBytesHex compares two byte slices like DiffBytes and reports the Old and
+// New content of every edit hex encoded, so that non-printable bytes are
+// visible. OldLen and NewLen still count bytes.
+func DiffBytesHex(old, updated []byte, windowSize int) ([]Edit, error) {
+	edits, err := DiffBytes(old, updated, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	for i, edit := range edits {
+		edits[i].Old = hex.EncodeToString([]byte(edit.Old))
+		edits[i].New = hex.EncodeToString([]byte(edit.New))
+	}
+	return edits, nil
+}
@@ -0,0 +1,122 @@
+package textcompare
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestDiffBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldData  []byte
+		updated  []byte
+		expected []Edit
+	}{
+		{"Added content at the end", []byte("hello"), []byte("hello world"), []Edit{{Op: Added, Start: 5, NewStart: 5, New: " world", NewLen: 6}}},
+		{"Deleted content at the end", []byte("hello world"), []byte("hello"), []Edit{{Op: Deleted, Start: 5, NewStart: 5, Old: " world", OldLen: 6}}},
+		{"Modified content in the middle", []byte("hello world"), []byte("hello xorld"), []Edit{{Op: Modified, Start: 6, NewStart: 6, Old: "w", New: "x", OldLen: 1, NewLen: 1}}},
+		{"Identical data", []byte{0, 1, 2}, []byte{0, 1, 2}, nil},
+		{"Invalid UTF-8", []byte{'a', 0xff, 'b'}, []byte{'a', 0xfe, 'b'}, []Edit{{Op: Modified, Start: 1, NewStart: 1, Old: "\xff", New: "\xfe", OldLen: 1, NewLen: 1}}},
+		{"Byte offsets of multibyte characters", []byte("añb"), []byte("aéb"), []Edit{{Op: Modified, Start: 2, NewStart: 2, Old: "\xb1", New: "\xa9", OldLen: 1, NewLen: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := DiffBytes(tt.oldData, tt.updated, 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+
+	// Test that applying the edits byte by byte gives the updated data
+	t.Run("Reconstruction", func(t *testing.T) {
+		oldData := []byte{0x00, 0x10, 0xff, 0x20, 0x30, 0xc3}
+		updated := []byte{0x00, 0xff, 0xff, 0x20, 0x31, 0xc3, 0xa9}
+		edits, err := DiffBytes(oldData, updated, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		var got []byte
+		pos := 0
+		for _, edit := range edits {
+			got = append(got, oldData[pos:edit.Start]...)
+			got = append(got, edit.New...)
+			pos = edit.Start + len(edit.Old)
+		}
+		got = append(got, oldData[pos:]...)
+		if !reflect.DeepEqual(got, updated) {
+			t.Errorf("Test failed. Expected: %x Got: %x", updated, got)
+		}
+	})
+
+	// Test that an invalid window size is rejected
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, err := DiffBytes([]byte("a"), []byte("b"), 0); err == nil {
+			t.Errorf("Test failed. Expected: an error Got: nil")
+		}
+	})
+
+	// Test that the data is compared in place, allocating far less than a copy of it
+	t.Run("No copy of the data", func(t *testing.T) {
+		oldData := bytes.Repeat([]byte{0xff, 'a'}, 1<<20)
+		updated := bytes.Clone(oldData)
+		updated[len(updated)/2] = 'b'
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		edits, err := DiffBytes(oldData, updated, 4)
+		runtime.ReadMemStats(&after)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 1 || edits[0].Start != len(oldData)/2 {
+			t.Fatalf("Test failed. Expected: one edit at %d Got: %+v", len(oldData)/2, edits)
+		}
+		if allocated := after.TotalAlloc - before.TotalAlloc; allocated >= uint64(len(oldData)) {
+			t.Errorf("Test failed. Expected: less than %d bytes allocated Got: %d", len(oldData), allocated)
+		}
+	})
+
+	// Test that the edits do not share memory with the data compared
+	t.Run("Content copied out", func(t *testing.T) {
+		oldData, updated := []byte("abc"), []byte("axc")
+		edits, err := DiffBytes(oldData, updated, 1)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		oldData[1], updated[1] = 'y', 'z'
+		if edits[0].Old != "b" || edits[0].New != "x" {
+			t.Errorf("Test failed. Expected: b and x Got: %s and %s", edits[0].Old, edits[0].New)
+		}
+	})
+}
+
+func TestDiffBytesHex(t *testing.T) {
+	// Test that the content of edits is hex encoded and lengths count bytes
+	tests := []struct {
+		name     string
+		oldData  []byte
+		updated  []byte
+		expected []Edit
+	}{
+		{"Embedded null replaced", []byte{'a', 0, 'b', 0, 'c'}, []byte{'a', 0, 0xff, 0, 'c'}, []Edit{{Op: Modified, Start: 2, NewStart: 2, Old: "62", New: "ff", OldLen: 1, NewLen: 1}}},
+		{"Nulls added", []byte{1, 2, 3}, []byte{1, 2, 3, 0, 0}, []Edit{{Op: Added, Start: 3, NewStart: 3, New: "0000", NewLen: 2}}},
+		{"Nulls deleted", []byte{0, 0, 7, 8}, []byte{7, 8}, []Edit{{Op: Deleted, Start: 0, NewStart: 0, Old: "0000", OldLen: 2}}},
+		{"Identical data", []byte{0, 1, 2}, []byte{0, 1, 2}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := DiffBytesHex(tt.oldData, tt.updated, 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+}
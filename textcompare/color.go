@@ -0,0 +1,13 @@
+package textcompare
+
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiReset = "\x1b[0m"
+)
+
+// FormatColor renders edits in the textual delta format produced by Diff,
+// with deletions in red and additions in green using ANSI escape codes.
+func FormatColor(edits []Edit) string {
+	return formatDelta(edits, deltaStart, ansiRed, ansiGreen, ansiReset)
+}
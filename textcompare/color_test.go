@@ -0,0 +1,32 @@
+package textcompare
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFormatColor(t *testing.T) {
+	edits := []Edit{
+		{Op: Deleted, Start: 0, Old: "ab", OldLen: 2},
+		{Op: Modified, Start: 3, Old: "d", New: "xy", OldLen: 1, NewLen: 2},
+		{Op: Added, Start: 6, New: "z", NewLen: 1},
+	}
+
+	// Test that deletions are red and additions green
+	t.Run("Colors", func(t *testing.T) {
+		expected := "Start character: 1 Length: 2/0 \x1b[31m[--- ab]\x1b[0m\n" +
+			"Start character: 4 Length: 1/2 \x1b[31m[--- d]\x1b[0m\x1b[32m[+++ xy]\x1b[0m\n" +
+			"Start character: 7 Length: 0/1 \x1b[32m[+++ z]\x1b[0m\n"
+		if got := FormatColor(edits); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that removing the escape codes gives the plain delta
+	t.Run("Plain delta", func(t *testing.T) {
+		plain := regexp.MustCompile("\x1b\\[[0-9]*m").ReplaceAllString(FormatColor(edits), "")
+		if plain != formatEdits(edits) {
+			t.Errorf("Test failed. Expected: %q Got: %q", formatEdits(edits), plain)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package textcompare
+
+// Comparer compares pairs of texts with the same settings, keeping the
+// buffers of the rolling hash searches from one comparison to the next, so
+// comparing many texts allocates less than calling DiffWithOptions for each.
+// A Comparer is not safe for concurrent use: goroutines comparing texts at
+// the same time need a Comparer each.
+type Comparer struct {
+	d          *differ
+	windowSize int
+}
+
+// NewComparer returns a Comparer applying opts to every comparison. The hash
+// parameters are validated once, here.
+func NewComparer(opts DiffOptions) (*Comparer, error) {
+	if err := opts.Hash.validate(); err != nil {
+		return nil, err
+	}
+	return &Comparer{d: newDiffer(opts), windowSize: opts.WindowSize}, nil
+}
+
+// Diff compares old against updated like DiffWithOptions with the settings
+// of the Comparer. Nothing of the previous comparison is carried over except
+// the buffers, and the edits returned are never reused.
+func (c *Comparer) Diff(old, updated string) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	c.d.reset()
+	return c.d.diff(old, updated, c.windowSize)
+}
@@ -0,0 +1,73 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComparer(t *testing.T) {
+	pairs := [][2]string{
+		{"hello world", "hello brave new world"},
+		{"I like cats", "I love dogs"},
+		{"héllo wörld", "hello world"},
+		{"same", "same"},
+		{"", "added"},
+		{"a much longer old text to compare first", "short"},
+	}
+
+	// Test that repeated comparisons give the same edits as DiffWithOptions
+	t.Run("Matches DiffWithOptions", func(t *testing.T) {
+		opts := DiffOptions{WindowSize: 2, MinMatch: 2}
+		c, err := NewComparer(opts)
+		if err != nil {
+			t.Fatalf("Test failed. Expected no error Got: %v", err)
+		}
+		for round := 0; round < 2; round++ {
+			for _, pair := range pairs {
+				got, err := c.Diff(pair[0], pair[1])
+				if err != nil {
+					t.Fatalf("Test failed. Expected no error Got: %v", err)
+				}
+				expected, _ := DiffWithOptions(pair[0], pair[1], opts)
+				if !reflect.DeepEqual(got, expected) {
+					t.Errorf("Test failed. %q Expected: %+v Got: %+v", pair, expected, got)
+				}
+			}
+		}
+	})
+
+	// Test that the edit limit applies to every comparison, not only the first
+	t.Run("Limit reset", func(t *testing.T) {
+		c, _ := NewComparer(DiffOptions{WindowSize: 1, MaxEdits: 1})
+		for i := 0; i < 2; i++ {
+			edits, err := c.Diff("abcdef", "aXcdeY")
+			if err != nil || len(edits) != 1 {
+				t.Errorf("Test failed. Expected: 1 edit Got: %+v %v", edits, err)
+			}
+		}
+	})
+
+	// Test that invalid hash parameters are rejected when creating the Comparer
+	t.Run("Invalid hash", func(t *testing.T) {
+		if _, err := NewComparer(DiffOptions{WindowSize: 2, Hash: HashConfig{Prime: 1}}); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func BenchmarkComparerDiff(b *testing.B) {
+	old, updated := manyChanges()
+	c, _ := NewComparer(DiffOptions{WindowSize: 4})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Diff(old, updated)
+	}
+}
+
+func BenchmarkDiffWithOptions(b *testing.B) {
+	old, updated := manyChanges()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DiffWithOptions(old, updated, DiffOptions{WindowSize: 4})
+	}
+}
@@ -0,0 +1,248 @@
+package textcompare
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	deltaPrefix   = "Start character: "
+	lengthPrefix  = "Length: "
+	deletedMarker = "[--- "
+	addedMarker   = "[+++ "
+	markerEnd     = "]"
+	// checksumPrefix starts the optional first line of a delta recording
+	// the checksum of the old text it applies to.
+	checksumPrefix = "Checksum: "
+)
+
+// deltaBase is the position of the first character of the old text in the
+// textual delta format. Edits always hold 0-based positions; the conversion to
+// and from the 1-based positions of the delta happens only when formatting and
+// parsing it.
+const deltaBase = 1
+
+// formatEdits renders edits in the textual delta format, one edit per line:
+// "Start character: N Length: O/M [--- old][+++ new]", where N is 1-based and
+// O and M are the lengths in runes of the old and new content. Backslashes,
+// "]" and line breaks in the content are escaped with a backslash, as "\\",
+// "\]", "\n" and "\r", so any content can be read back.
+func formatEdits(edits []Edit) string {
+	return formatDelta(edits, deltaStart, "", "", "")
+}
+
+// deltaStart renders the position of edit as the start marker of the textual
+// delta format.
+func deltaStart(edit Edit) string {
+	return deltaPrefix + strconv.Itoa(edit.Start+deltaBase)
+}
+
+// formatDelta renders edits in the textual delta format, with the position of
+// every edit rendered by position, and its deleted and added parts written
+// between the given prefixes and reset.
+func formatDelta(edits []Edit, position func(Edit) string, deletedPrefix, addedPrefix, reset string) string {
+	var sb strings.Builder
+	for _, edit := range expandMoves(edits) {
+		sb.WriteString(position(edit))
+		sb.WriteString(" " + lengthPrefix)
+		sb.WriteString(strconv.Itoa(utf8.RuneCountInString(edit.Old)) + "/" + strconv.Itoa(utf8.RuneCountInString(edit.New)))
+		sb.WriteString(" ")
+		if edit.Op != Added {
+			sb.WriteString(deletedPrefix + deletedMarker + escapeContent(edit.Old) + markerEnd + reset)
+		}
+		if edit.Op != Deleted {
+			sb.WriteString(addedPrefix + addedMarker + escapeContent(edit.New) + markerEnd + reset)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// parseDeltaLine reads a single line of the textual delta format back into
+// an edit with a 0-based position. The reported bool is false when the line
+// has no start marker. The lengths are optional, so deltas written before they
+// were added are still read, but they must match the content when present.
+func parseDeltaLine(line string) (Edit, bool, error) {
+	if !strings.HasPrefix(line, deltaPrefix) {
+		return Edit{}, false, nil
+	}
+	rest := strings.TrimPrefix(line, deltaPrefix)
+	startStr, content, _ := strings.Cut(rest, " ")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return Edit{}, true, &CustomError{message: fmt.Sprintf("invalid start: %v", err)}
+	}
+	if start < deltaBase {
+		// The first character of the old text is at deltaBase, and edits at
+		// the very start of it are written there
+		return Edit{}, true, &CustomError{message: fmt.Sprintf("invalid start: %d is before the first character %d", start, deltaBase)}
+	}
+	oldLen, newLen := -1, -1
+	if strings.HasPrefix(content, lengthPrefix) {
+		var lengths string
+		lengths, content, _ = strings.Cut(strings.TrimPrefix(content, lengthPrefix), " ")
+		oldStr, newStr, _ := strings.Cut(lengths, "/")
+		if oldLen, err = strconv.Atoi(oldStr); err != nil {
+			return Edit{}, true, &CustomError{message: fmt.Sprintf("invalid lengths: %v", err)}
+		}
+		if newLen, err = strconv.Atoi(newStr); err != nil {
+			return Edit{}, true, &CustomError{message: fmt.Sprintf("invalid lengths: %v", err)}
+		}
+	}
+	previous, next := "", ""
+	if strings.HasPrefix(content, deletedMarker) {
+		if previous, content, err = readMarked(content[len(deletedMarker):]); err != nil {
+			return Edit{}, true, err
+		}
+	}
+	if strings.HasPrefix(content, addedMarker) {
+		if next, content, err = readMarked(content[len(addedMarker):]); err != nil {
+			return Edit{}, true, err
+		}
+	}
+	if content != "" {
+		return Edit{}, true, &CustomError{message: fmt.Sprintf("unexpected %q after the content of the edit", content)}
+	}
+	edit := newEdit(start-deltaBase, previous, next)
+	if oldLen >= 0 && (oldLen != edit.OldLen || newLen != edit.NewLen) {
+		return Edit{}, true, &CustomError{message: fmt.Sprintf("lengths %d/%d do not match the content of the edit", oldLen, newLen)}
+	}
+	return edit, true, nil
+}
+
+// checksum returns the FNV-1a hash of text in hexadecimal, the form a delta
+// records the old text it applies to with.
+func checksum(text string) string {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// isChecksumLine reports whether line i of a delta is its checksum line,
+// which can only be the first one.
+func isChecksumLine(i int, line string) bool {
+	return i == 0 && strings.HasPrefix(line, checksumPrefix)
+}
+
+// verifyChecksum checks that text is the one the checksum line of delta was
+// recorded for. Deltas without a checksum line are accepted as they are.
+func verifyChecksum(text, delta string) error {
+	line, _, _ := strings.Cut(delta, "\n")
+	if !isChecksumLine(0, line) {
+		return nil
+	}
+	if expected, got := strings.TrimPrefix(line, checksumPrefix), checksum(text); expected != got {
+		return &CustomError{message: fmt.Sprintf("checksum mismatch: the delta applies to a text with checksum %s, got %s", expected, got)}
+	}
+	return nil
+}
+
+// contentEscaper escapes the characters that would end the content of an
+// edit early in the textual delta format: the marker end and the newline
+// ending the line, plus the escape character itself.
+var contentEscaper = strings.NewReplacer(`\`, `\\`, markerEnd, `\`+markerEnd, "\n", `\n`, "\r", `\r`)
+
+// escapeContent escapes content to be written between the markers of the
+// textual delta format.
+func escapeContent(content string) string {
+	return contentEscaper.Replace(content)
+}
+
+// readMarked reads the escaped content that s starts with up to its
+// unescaped marker end, and returns the unescaped content and what follows
+// the marker end.
+func readMarked(s string) (string, string, error) {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case markerEnd[0]:
+			return sb.String(), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", &CustomError{message: "unterminated escape in the content of the edit"}
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			default:
+				sb.WriteByte(s[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", "", &CustomError{message: fmt.Sprintf("missing %q after the content of the edit", markerEnd)}
+}
+
+// applyEdits rebuilds the updated text in a single left-to-right pass. Edit
+// positions refer to the original old text, so the unchanged runs between
+// edits are copied from it while the replaced content is taken from the edits.
+func applyEdits(old string, edits []Edit) string {
+	oldRunes := []rune(old)
+	var sb strings.Builder
+	pos := 0
+	for _, edit := range expandMoves(edits) {
+		start := min(max(edit.Start, pos), len(oldRunes))
+		sb.WriteString(string(oldRunes[pos:start]))
+		sb.WriteString(edit.New)
+		pos = min(start+utf8.RuneCountInString(edit.Old), len(oldRunes))
+	}
+	sb.WriteString(string(oldRunes[pos:]))
+	return sb.String()
+}
+
+// ApplyPatch applies a delta produced by Diff to old and returns the
+// resulting text. An error is returned when a line of the delta is malformed,
+// when its edits do not fit old, as Patch.Apply checks, or when the delta
+// carries a checksum, as DiffWithChecksum writes, and old is not the text it
+// was made for.
+func ApplyPatch(old, delta string) (string, error) {
+	patch, err := ParsePatch(delta)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksum(old, delta); err != nil {
+		return "", err
+	}
+	return patch.Apply(old)
+}
+
+// ReplaceDelta applies a delta produced by Diff to old and returns the
+// resulting text. It returns old unchanged when the delta is malformed, does
+// not fit old or its checksum does not match old; use ApplyPatch to tell these
+// cases apart.
+func ReplaceDelta(old, delta string) string {
+	result, err := ApplyPatch(old, delta)
+	if err != nil {
+		return old
+	}
+	return result
+}
+
+// ReverseDelta undoes a delta produced by Diff, turning the updated text back
+// into the old one. A malformed delta, or one whose checksum does not match
+// the old text rebuilt, leaves updated unchanged.
+func ReverseDelta(updated, delta string) string {
+	var edits []Edit
+	for i, value := range strings.Split(delta, "\n") {
+		if len(value) == 0 || isChecksumLine(i, value) {
+			continue
+		}
+		edit, ok, err := parseDeltaLine(value)
+		if !ok || err != nil {
+			return updated
+		}
+		edits = append(edits, edit)
+	}
+	old := applyEdits(updated, InvertEdits(edits))
+	if verifyChecksum(old, delta) != nil {
+		return updated
+	}
+	return old
+}
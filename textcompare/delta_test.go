@@ -0,0 +1,382 @@
+package textcompare
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestReplaceDelta(t *testing.T) {
+	// Test that positions of later edits refer to the original text
+	t.Run("Deletion before addition", func(t *testing.T) {
+		oldText := "abcdefgh"
+		delta := "Start character: 1 [--- ab]\nStart character: 8 [+++ xyz]\n"
+		expected := "cdefgxyzh"
+		if got := ReplaceDelta(oldText, delta); got != expected {
+			t.Errorf("Test failed. Expected: %s Got: %s", expected, got)
+		}
+	})
+
+	// Test modifications of different lengths followed by a deletion
+	t.Run("Mixed edits", func(t *testing.T) {
+		oldText := "the cat sat on the mat"
+		delta := "Start character: 5 [--- cat][+++ tiger]\nStart character: 9 [--- sat][+++ lay]\nStart character: 15 [---  the]\n"
+		expected := "the tiger lay on mat"
+		if got := ReplaceDelta(oldText, delta); got != expected {
+			t.Errorf("Test failed. Expected: %s Got: %s", expected, got)
+		}
+	})
+	// Test that a delta built from 0-based edits is reconstructed
+	t.Run("Zero-based checkString", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "jello world!"
+		delta := mustCheckString(t, oldText, updatedText, 2, 0)
+		if got := ReplaceDelta(oldText, delta); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+		if !strings.HasPrefix(delta, "Start character: 1 ") {
+			t.Errorf("Test failed. Expected: a 1-based delta Got: %q", delta)
+		}
+	})
+
+	// Test that an offset delta applies to the enclosing text
+	t.Run("Offset checkString", func(t *testing.T) {
+		prefix := "say: "
+		delta := mustCheckString(t, "hello world", "hello there", 2, len(prefix))
+		expected := prefix + "hello there"
+		if got := ReplaceDelta(prefix+"hello world", delta); got != expected {
+			t.Errorf("Test failed. Expected: %s Got: %s", expected, got)
+		}
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	// Test that a well formed delta is applied
+	t.Run("Valid delta", func(t *testing.T) {
+		got, err := ApplyPatch("hello world", "Start character: 7 [--- w][+++ W]\n")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got != "hello World" {
+			t.Errorf("Test failed. Expected: hello World Got: %s", got)
+		}
+	})
+
+	// Test that malformed delta lines surface as errors
+	t.Run("Malformed delta", func(t *testing.T) {
+		for _, delta := range []string{"garbage line\n", "Start character: x [--- a]\n", "Start character: 0 [+++ a]\n"} {
+			got, err := ApplyPatch("hello world", delta)
+			if _, ok := err.(*CustomError); !ok {
+				t.Errorf("Test failed. Delta %q Expected a *CustomError Got: %v", delta, err)
+			}
+			if got != "" {
+				t.Errorf("Test failed. Expected: no text Got: %s", got)
+			}
+		}
+	})
+
+	// Test that a delta whose edits do not fit the text is an error instead of corrupt text
+	t.Run("Edits out of range", func(t *testing.T) {
+		delta, _ := Diff("hello world, how are you", "hello there, how are we", 2)
+		got, err := ApplyPatch("hi", delta)
+		if _, ok := err.(*CustomError); !ok || got != "" {
+			t.Errorf("Test failed. Expected a *CustomError Got: %q %v", got, err)
+		}
+		if got := ReplaceDelta("hi", delta); got != "hi" {
+			t.Errorf("Test failed. Expected: hi Got: %s", got)
+		}
+	})
+
+	// Test that a delta whose content does not match the text is an error
+	t.Run("Content mismatch", func(t *testing.T) {
+		if got, err := ApplyPatch("hello world", "Start character: 7 [--- x][+++ W]\n"); err == nil {
+			t.Errorf("Test failed. Expected an error Got: %s", got)
+		}
+	})
+
+	// Test that ReplaceDelta no longer pollutes the result
+	t.Run("ReplaceDelta keeps old", func(t *testing.T) {
+		if got := ReplaceDelta("hello world", "garbage line\n"); got != "hello world" {
+			t.Errorf("Test failed. Expected: hello world Got: %s", got)
+		}
+	})
+}
+
+func TestDiffWithChecksum(t *testing.T) {
+	old, updated := "hello world", "hello brave new world"
+	delta, err := DiffWithChecksum(old, updated, 2)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+
+	// Test that the checksum line precedes the delta of Diff
+	t.Run("Format", func(t *testing.T) {
+		plain, _ := Diff(old, updated, 2)
+		if expected := "Checksum: " + checksum(old) + "\n" + plain; delta != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, delta)
+		}
+	})
+
+	// Test that the delta applies to the text it was made for
+	t.Run("Right base", func(t *testing.T) {
+		got, err := ApplyPatch(old, delta)
+		if err != nil || got != updated {
+			t.Errorf("Test failed. Expected: %s Got: %s %v", updated, got, err)
+		}
+		if got := ReverseDelta(got, delta); got != old {
+			t.Errorf("Test failed. Expected: %s Got: %s", old, got)
+		}
+	})
+
+	// Test that applying the delta to another text fails instead of corrupting it
+	t.Run("Wrong base", func(t *testing.T) {
+		wrong := "hello wide world"
+		got, err := ApplyPatch(wrong, delta)
+		if _, ok := err.(*CustomError); !ok || got != "" {
+			t.Errorf("Test failed. Expected a *CustomError Got: %q %v", got, err)
+		}
+		if got := ReplaceDelta(wrong, delta); got != wrong {
+			t.Errorf("Test failed. Expected: %s Got: %s", wrong, got)
+		}
+		if got := ReverseDelta("hello brave new world!", delta); got != "hello brave new world!" {
+			t.Errorf("Test failed. Expected: the text unchanged Got: %s", got)
+		}
+	})
+
+	// Test that the checksum line is skipped when parsing the edits
+	t.Run("Parsed edits", func(t *testing.T) {
+		patch, err := ParsePatch(delta)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected, _ := DiffEdits(old, updated, 2)
+		if !reflect.DeepEqual([]Edit(patch), expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, patch)
+		}
+	})
+
+	// Test that a checksum line is only accepted before the edits
+	t.Run("Checksum after the edits", func(t *testing.T) {
+		plain, _ := Diff(old, updated, 2)
+		if _, err := ParsePatch(plain + "Checksum: " + checksum(old) + "\n"); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestEditsAtStart(t *testing.T) {
+	// Test edits at the first character of the old text, where the first difference is at index 0
+	tests := []struct {
+		name     string
+		old      string
+		updated  string
+		expected string
+	}{
+		{"First character modified", "hello world", "jello world", "Start character: 1 Length: 1/1 [--- h][+++ j]\n"},
+		{"Prepended content", "hello world", "my hello world", "Start character: 1 Length: 0/3 [+++ my ]\n"},
+		{"First character deleted", "hello world", "ello world", "Start character: 1 Length: 1/0 [--- h]\n"},
+		{"First character modified with a later edit", "hello world", "jello world!", "Start character: 1 Length: 1/1 [--- h][+++ j]\nStart character: 12 Length: 0/1 [+++ !]\n"},
+		{"Prepended content with a later edit", "hello world", "my hello world!", "Start character: 1 Length: 0/3 [+++ my ]\nStart character: 12 Length: 0/1 [+++ !]\n"},
+		{"First character deleted with a later edit", "hello world", "ello world!", "Start character: 1 Length: 1/0 [--- h]\nStart character: 12 Length: 0/1 [+++ !]\n"},
+		{"Single character replaced", "h", "j", "Start character: 1 Length: 1/1 [--- h][+++ j]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 4; windowSize++ {
+				delta := mustCheckString(t, tt.old, tt.updated, windowSize, 0)
+				if delta != tt.expected {
+					t.Errorf("Test failed. Window %d Expected: %q Got: %q", windowSize, tt.expected, delta)
+				}
+				got, err := ApplyPatch(tt.old, delta)
+				if err != nil || got != tt.updated {
+					t.Errorf("Test failed. Window %d Expected: %s Got: %s %v", windowSize, tt.updated, got, err)
+				}
+				if got := ReverseDelta(tt.updated, delta); got != tt.old {
+					t.Errorf("Test failed. Window %d Expected: %s Got: %s", windowSize, tt.old, got)
+				}
+			}
+		})
+	}
+
+	// Test that a start before the first character is rejected instead of being clamped
+	t.Run("Start before the first character", func(t *testing.T) {
+		for _, delta := range []string{"Start character: 0 [+++ X]\n", "Start character: -3 [--- h]\n"} {
+			if _, err := ParsePatch(delta); err == nil {
+				t.Errorf("Test failed. Delta %q Expected an error", delta)
+			}
+		}
+	})
+}
+
+func TestDeltaEscaping(t *testing.T) {
+	// Test that content holding the delta markers is reconstructed
+	tests := []struct {
+		name    string
+		oldText string
+		updated string
+	}{
+		{"Deleted marker", "keep [--- this] text", "keep text"},
+		{"Added marker", "list", "list [+++ item][--- x]"},
+		{"Modified markers", "a [--- b] c", "a [+++ b] c"},
+		{"Backslashes", `path\to\file`, `path\\new\]file\n`},
+		{"Line breaks", "one\ntwo\r\nthree", "one\nzwei\r\n\nthree"},
+		{"Start prefix", "text", "Start character: 1 [+++ text]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 3; windowSize++ {
+				delta := mustCheckString(t, tt.oldText, tt.updated, windowSize, 0)
+				got, err := ApplyPatch(tt.oldText, delta)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v (delta %q)", err, delta)
+				}
+				if got != tt.updated {
+					t.Errorf("Test failed. Expected: %q Got: %q (delta %q)", tt.updated, got, delta)
+				}
+				if edits, _ := DiffEdits(tt.oldText, tt.updated, windowSize); strings.Count(delta, "\n") != len(edits) {
+					t.Errorf("Test failed. Expected: one line per edit Got: %q", delta)
+				}
+			}
+		})
+	}
+
+	// Test the escaped form of the content
+	t.Run("Escaped content", func(t *testing.T) {
+		delta := Patch{newEdit(0, "a]b", "c\\d\n")}.String()
+		expected := `Start character: 1 Length: 3/4 [--- a\]b][+++ c\\d\n]` + "\n"
+		if delta != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, delta)
+		}
+	})
+
+	// Test that unterminated content is rejected
+	t.Run("Unterminated content", func(t *testing.T) {
+		for _, delta := range []string{"Start character: 1 [--- ab\n", "Start character: 1 [+++ ab\\]\n", "Start character: 1 [--- a] b\n"} {
+			if _, err := ParsePatch(delta); err == nil {
+				t.Errorf("Test failed. Delta %q Expected an error", delta)
+			}
+		}
+	})
+}
+
+func TestDeltaRoundTrip(t *testing.T) {
+	// Test that applying the computed delta always reconstructs the updated text
+	r := rand.New(rand.NewSource(1))
+	alphabet := []rune("ab cñ")
+	randomText := func() string {
+		text := make([]rune, r.Intn(16))
+		for i := range text {
+			text[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return string(text)
+	}
+	for i := 0; i < 2000; i++ {
+		oldText, updatedText := randomText(), randomText()
+		windowSize := r.Intn(4) + 1
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		if got := ReplaceDelta(oldText, delta); got != updatedText {
+			t.Fatalf("Test failed. Old: %q Updated: %q Window: %d Delta: %q Got: %q", oldText, updatedText, windowSize, delta, got)
+		}
+	}
+}
+
+func TestReverseDelta(t *testing.T) {
+	// Test that reversing an applied delta gives back the original text
+	tests := []struct {
+		name    string
+		oldText string
+		updated string
+	}{
+		{"Added content", "hello", "hello world"},
+		{"Deleted content", "hello world", "hello"},
+		{"Modified content", "hello world", "jello worlx"},
+		{"Mixed content", "the cat sat", "a dog sat down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delta := mustCheckString(t, tt.oldText, tt.updated, 2, 0)
+			updatedText := ReplaceDelta(tt.oldText, delta)
+			if got := ReverseDelta(updatedText, delta); got != tt.oldText {
+				t.Errorf("Test failed. Expected: %s Got: %s", tt.oldText, got)
+			}
+		})
+	}
+
+	// Test the inverse of each kind of edit
+	t.Run("Inverted edits", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 0, Old: "ab", OldLen: 2},
+			{Op: Modified, Start: 3, Old: "d", New: "xy", OldLen: 1, NewLen: 2},
+			{Op: Added, Start: 6, New: "z", NewLen: 1},
+		}
+		expected := []Edit{
+			{Op: Added, Start: 0, NewStart: 0, New: "ab", NewLen: 2},
+			{Op: Modified, Start: 1, NewStart: 3, Old: "xy", New: "d", OldLen: 2, NewLen: 1},
+			{Op: Deleted, Start: 5, NewStart: 6, Old: "z", OldLen: 1},
+		}
+		if got := InvertEdits(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+		if got := applyEdits(applyEdits("abcdefg", edits), InvertEdits(edits)); got != "abcdefg" {
+			t.Errorf("Test failed. Expected: abcdefg Got: %s", got)
+		}
+	})
+}
+
+func TestEditLengths(t *testing.T) {
+	// Test that the lengths match the content of every edit
+	pairs := [][2]string{
+		{"hello world", "hello there"},
+		{"the cat sat", "a dog sat down"},
+		{"año nuevo 👋", "año viejo 🌍!"},
+		{"", "new text"},
+		{"old text", ""},
+	}
+	for _, pair := range pairs {
+		edits, err := DiffEdits(pair[0], pair[1], 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		edits = append(edits, DiffWords(pair[0], pair[1])...)
+		for _, edit := range edits {
+			if edit.OldLen != utf8.RuneCountInString(edit.Old) || edit.NewLen != utf8.RuneCountInString(edit.New) {
+				t.Errorf("Test failed. Expected: %d/%d Got: %d/%d", utf8.RuneCountInString(edit.Old), utf8.RuneCountInString(edit.New), edit.OldLen, edit.NewLen)
+			}
+		}
+	}
+
+	// Test that the lengths are written in the delta and read back
+	t.Run("Textual format", func(t *testing.T) {
+		delta := Patch{newEdit(2, "cat", "tiger")}.String()
+		if expected := "Start character: 3 Length: 3/5 [--- cat][+++ tiger]\n"; delta != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, delta)
+		}
+		patch, err := ParsePatch(delta)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if patch[0].OldLen != 3 || patch[0].NewLen != 5 {
+			t.Errorf("Test failed. Expected: 3/5 Got: %d/%d", patch[0].OldLen, patch[0].NewLen)
+		}
+	})
+
+	// Test that deltas without lengths are still read
+	t.Run("Legacy format", func(t *testing.T) {
+		patch, err := ParsePatch("Start character: 3 [--- cat][+++ tiger]\n")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if patch[0].OldLen != 3 || patch[0].NewLen != 5 {
+			t.Errorf("Test failed. Expected: 3/5 Got: %d/%d", patch[0].OldLen, patch[0].NewLen)
+		}
+	})
+
+	// Test that lengths contradicting the content are rejected
+	t.Run("Mismatched lengths", func(t *testing.T) {
+		if _, err := ParsePatch("Start character: 3 Length: 2/5 [--- cat][+++ tiger]\n"); err == nil {
+			t.Errorf("Test failed. Expected: an error Got: nil")
+		}
+	})
+}
@@ -0,0 +1,594 @@
+package textcompare
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"unicode/utf8"
+)
+
+// validateWindow rejects window sizes that cannot hold any character.
+func validateWindow(windowSize int) error {
+	if windowSize <= 0 {
+		return &CustomError{message: fmt.Sprintf("window size must be positive, got %d", windowSize)}
+	}
+	return nil
+}
+
+// recoverPanic turns a panic of the comparison into the error stored in
+// err, logging the value recovered, so a bug in the engine does not crash
+// the program embedding it. The entry points defer it on their named error.
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		log.Printf("textcompare: recovered from panic: %v", r)
+		*err = &CustomError{message: fmt.Sprintf("comparison failed: %v", r)}
+	}
+}
+
+// clampWindow returns the window size used to compare texts of len1 and len2
+// runes: windowSize, shrunk to the length of the shortest text when it does
+// not fit in it, and never below 1.
+func clampWindow(len1, len2, windowSize int) int {
+	return max(1, min(windowSize, len1, len2))
+}
+
+// SearchFirstDif returns the text shared by text1 and text2 up to their first
+// difference, the index of that difference and whether the end of one of the
+// texts was reached. It fails when a text is empty or the window size is not
+// positive. A window larger than one of the texts is clamped to the shortest.
+func SearchFirstDif(text1, text2 string, windowSize int) (equal string, index int, isEnd bool, err error) {
+	defer recoverPanic(&err)
+	return newDiffer(DiffOptions{}).searchFirstDif(text1, text2, windowSize)
+}
+
+// FirstDifference reports the rune index where old and updated first
+// diverge, without computing the edits. When the texts are equal it returns
+// -1 and true. When one text is a prefix of the other the index is the length
+// of the shorter one.
+func FirstDifference(old, updated string, windowSize int) (int, bool, error) {
+	if err := validateWindow(windowSize); err != nil {
+		return 0, false, err
+	}
+	if len(old) == len(updated) && old == updated {
+		return -1, true, nil
+	}
+	shortest := min(utf8.RuneCountInString(old), utf8.RuneCountInString(updated))
+	if shortest == 0 {
+		return 0, false, nil
+	}
+	_, index, isEnd, err := SearchFirstDif(old, updated, clampWindow(shortest, shortest, windowSize))
+	if err != nil {
+		return 0, false, err
+	}
+	if isEnd {
+		// Every window matched up to the end of the shorter text
+		return shortest, false, nil
+	}
+	return index, false, nil
+}
+
+func (d *differ) searchFirstDif(text1, text2 string, windowSize int) (string, int, bool, error) {
+	if err := validateWindow(windowSize); err != nil {
+		return "", 0, false, err
+	}
+	if text1 == "" || text2 == "" {
+		return "", 0, false, &CustomError{message: "cannot search for differences in an empty text"}
+	}
+	windowSize = clampWindow(d.length(text1), d.length(text2), windowSize)
+	// We take the two instances of TextSearch for the two texts
+	text1Search, text2Search := &d.searches[0], &d.searches[1]
+	d.startSearch(text1Search, text1, windowSize)
+	d.startSearch(text2Search, text2, windowSize)
+
+	// Variables to track the index of the first difference
+	index := 0
+	text1Search.SetStart(index, 1)
+	text2Search.SetStart(index, 1)
+
+	boolRes := false
+	// If the windows are different and the window size is 1, we find the exact index of the first different character
+	if !sameWindow(text1Search, text2Search) {
+		return "", index, boolRes, nil
+	}
+
+	// Iterate until we reach the end of one of the texts
+	for {
+		if err := d.cancelled(index); err != nil {
+			return "", 0, false, err
+		}
+		// If the windows are different, we find the first difference
+		if !sameWindow(text1Search, text2Search) {
+			// Reduce the window size until finding the exact index of the first different character
+			for i := 1; i < windowSize; i++ {
+				// Volver a calcular el hash desde el punto donde se detectó la diferencia
+				text1Search.SetStart(index, i)
+				text2Search.SetStart(index, i)
+
+				// If the windows are different and the window size is 1, we have found the exact index of the first different character
+				if !sameWindow(text1Search, text2Search) {
+					break
+				} else {
+					index++
+				}
+
+			}
+			break
+		} else {
+			// Advance the windows
+			text1Search.advance()
+			text2Search.advance()
+
+			// We increment the index
+			index++
+		}
+
+		// Check if we have reached the end of either of the texts
+		if err := text1Search.lastError; err != nil || text2Search.lastError != nil {
+			boolRes = true
+			break
+		}
+	}
+
+	// Build the text string that is the same in both strings up to the first difference
+	index = min(index, d.length(text1))
+	equalText := d.prefix(text1, index)
+
+	return equalText, index, boolRes, nil
+}
+
+// searchAddedContent looks for content inserted at the start of text2 by
+// moving a window over text2 until it matches the window at the start of
+// text1. It returns the added content, the indexes where text1 and text2
+// continue after it and whether the texts realigned. When they do not, all of
+// text2 is added.
+func (d *differ) searchAddedContent(text1, text2 string, windowSize int) (string, int, int, bool) {
+	index, found := d.searchRealign(text1, text2, windowSize)
+	return d.content(text2, index), 0, index, found
+}
+
+// searchDeletedContent looks for content removed from the start of text1,
+// like searchAddedContent with the roles of the texts swapped.
+func (d *differ) searchDeletedContent(text1, text2 string, windowSize int) (string, int, int, bool) {
+	index, found := d.searchRealign(text2, text1, windowSize)
+	return d.content(text1, index), index, 0, found
+}
+
+// searchRealign moves a window over moving until it matches the window at the
+// start of fixed, and returns the index reached and whether they matched.
+// Close to the end of moving the windows shrink to the characters left, so a
+// single character tail, such as a trailing newline, is still realigned on.
+// When no window matches the index is the length of moving.
+func (d *differ) searchRealign(fixed, moving string, windowSize int) (int, bool) {
+	fixedLen, movingLen := d.length(fixed), d.length(moving)
+	if fixedLen == 0 || movingLen == 0 {
+		return movingLen, false
+	}
+	window := clampWindow(fixedLen, movingLen, windowSize)
+	fixedSearch, movingSearch := &d.searches[0], &d.searches[1]
+	d.startSearch(fixedSearch, fixed, window)
+	d.startSearch(movingSearch, moving, window)
+	for index := 0; index < movingLen; index++ {
+		if d.cancelled(index) != nil {
+			// The caller reports the cancellation
+			return movingLen, false
+		}
+		if index > 0 {
+			if index+window <= movingLen {
+				movingSearch.advance()
+			} else {
+				// Tail of moving: compare what is left of it
+				movingSearch.SetStart(index, movingLen-index)
+				fixedSearch.SetStart(0, movingLen-index)
+			}
+		}
+		if sameWindow(fixedSearch, movingSearch) {
+			return index, true
+		}
+	}
+	return movingLen, false
+}
+
+// searchModifiedContent scans text1 and text2 with two pointers moving in
+// lockstep from their first difference, collecting the characters replaced on
+// each side until the texts realign on an equal window at the same offset.
+// Near the end of the shortest text the window shrinks to the characters left,
+// so a change right before the end still realigns on the shared tail. When
+// texts of different lengths never realign no modification is reported, so
+// the caller can look for an addition or deletion instead.
+func (d *differ) searchModifiedContent(text1, text2 string, windowSize int) (string, string, int, int, bool) {
+	len1, len2 := d.length(text1), d.length(text2)
+	shortest := min(len1, len2)
+	if shortest == 0 {
+		return "", "", 0, 0, false
+	}
+	window := clampWindow(len1, len2, windowSize)
+	text1Search, text2Search := &d.searches[0], &d.searches[1]
+	d.startSearch(text1Search, text1, window)
+	d.startSearch(text2Search, text2, window)
+
+	index := 0
+	for index < shortest && !sameWindow(text1Search, text2Search) {
+		if d.cancelled(index) != nil {
+			// The caller reports the cancellation
+			return "", "", 0, 0, false
+		}
+		index++
+		if index+window <= shortest {
+			// Both windows still fit: roll them one character
+			text1Search.advance()
+			text2Search.advance()
+		} else if index < shortest {
+			// Tail of the shortest text: compare what is left of it
+			text1Search.SetStart(index, shortest-index)
+			text2Search.SetStart(index, shortest-index)
+		}
+	}
+	realigned := index < shortest || len1 == len2
+	return d.content(text1, index), d.content(text2, index), index, index, index > 0 && realigned
+}
+
+// checkString compares old against updated and returns the textual delta.
+// oldGeneralIndex is the 0-based position of old inside the text the delta
+// applies to, 0 when old is the whole text.
+func checkString(old, updated string, windowSize int, oldGeneralIndex int) (string, error) {
+	edits, err := newDiffer(DiffOptions{}).collectEdits(old, updated, windowSize, oldGeneralIndex)
+	if err != nil {
+		return "", err
+	}
+	return formatEdits(edits), nil
+}
+
+// CommonPrefix returns the leading text shared by a and b, the equal text
+// SearchFirstDif reports, found by comparing both texts directly instead of
+// window by window. Multibyte characters are never split.
+func CommonPrefix(a, b string) string {
+	end := 0
+	for end < len(a) && end < len(b) {
+		_, size := utf8.DecodeRuneInString(a[end:])
+		if end+size > len(b) || a[end:end+size] != b[end:end+size] {
+			break
+		}
+		end += size
+	}
+	return a[:end]
+}
+
+// CommonPrefixSuffix returns the length in runes of the longest common prefix
+// of a and b, and of the longest common suffix of what remains after it.
+func CommonPrefixSuffix(a, b string) (prefixLen, suffixLen int) {
+	for len(a) > 0 && len(b) > 0 {
+		ra, sizeA := utf8.DecodeRuneInString(a)
+		rb, sizeB := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			break
+		}
+		a, b = a[sizeA:], b[sizeB:]
+		prefixLen++
+	}
+	for len(a) > 0 && len(b) > 0 {
+		ra, sizeA := utf8.DecodeLastRuneInString(a)
+		rb, sizeB := utf8.DecodeLastRuneInString(b)
+		if ra != rb {
+			break
+		}
+		a, b = a[:len(a)-sizeA], b[:len(b)-sizeB]
+		suffixLen++
+	}
+	return prefixLen, suffixLen
+}
+
+// commonPrefixSuffix returns the lengths CommonPrefixSuffix does, in bytes
+// when comparing byte by byte.
+func (d *differ) commonPrefixSuffix(a, b string) (prefixLen, suffixLen int) {
+	if !d.bytewise {
+		return CommonPrefixSuffix(a, b)
+	}
+	for prefixLen < len(a) && prefixLen < len(b) && a[prefixLen] == b[prefixLen] {
+		prefixLen++
+	}
+	a, b = a[prefixLen:], b[prefixLen:]
+	for suffixLen < len(a) && suffixLen < len(b) && a[len(a)-1-suffixLen] == b[len(b)-1-suffixLen] {
+		suffixLen++
+	}
+	return prefixLen, suffixLen
+}
+
+// collectEdits checks for differences between two texts and returns them as
+// edits whose Start is offset by oldGeneralIndex. The common prefix and suffix
+// are trimmed first so that only the differing middle is searched.
+func (d *differ) collectEdits(old, updated string, windowSize int, oldGeneralIndex int) ([]Edit, error) {
+	if err := validateWindow(windowSize); err != nil {
+		return nil, err
+	}
+	// Identical texts need no window work at all
+	if len(old) == len(updated) && old == updated {
+		return nil, nil
+	}
+	d.warnClamp(old, updated, windowSize)
+	if d.bytewise {
+		// Every byte is below ByteBase, and the texts need not be valid UTF-8
+		d.hash.Base = ByteBase
+	} else if d.alphabet {
+		d.hash.Base = alphabetBase(old, updated)
+	}
+	prefixLen, suffixLen := d.commonPrefixSuffix(old, updated)
+	if d.bytewise {
+		old, updated = old[prefixLen:len(old)-suffixLen], updated[prefixLen:len(updated)-suffixLen]
+		oldGeneralIndex += prefixLen
+	} else if prefixLen > 0 || suffixLen > 0 {
+		oldRunes, updatedRunes := []rune(old), []rune(updated)
+		old = string(oldRunes[prefixLen : len(oldRunes)-suffixLen])
+		updated = string(updatedRunes[prefixLen : len(updatedRunes)-suffixLen])
+		oldGeneralIndex += prefixLen
+	}
+	edits, err := d.collectMiddleEdits(old, updated, windowSize, oldGeneralIndex)
+	if err != nil {
+		return nil, err
+	}
+	return setNewStarts(edits), nil
+}
+
+// collectMiddleEdits checks for differences between two texts and returns
+// them as edits whose Start is offset by oldGeneralIndex. Every pass of the
+// loop finds one edit and continues with the rest of both texts, so long
+// inputs with many differences do not grow the stack.
+// The edit of a pass is chosen with a fixed precedence, so the same texts
+// always give the same edits: a modification realigning both texts at the
+// same offset comes first, unless an addition or a deletion no longer than
+// it realigns them too, as a pure insertion followed by repeated characters
+// also reads as a replacement. A run of the character before the difference
+// grown or shrunk on one side is read as an addition or deletion at the end
+// of the run, as the windows over it all look alike and may realign anywhere
+// in it. When both an addition and a deletion realign the one touching fewer
+// characters wins, an addition winning a tie. When nothing realigns the rest
+// of both texts is a single replacement.
+func (d *differ) collectMiddleEdits(old, updated string, windowSize int, oldGeneralIndex int) ([]Edit, error) {
+	var edits []Edit
+	for old != "" || updated != "" {
+		if err := d.cancelled(0); err != nil {
+			return nil, err
+		}
+		if d.limitReached() {
+			break
+		}
+		// Nothing left to align on one of the sides
+		if old == "" || updated == "" {
+			return d.flush(appendEdit(edits, d.edit(oldGeneralIndex, old, updated)))
+		}
+		windowSize = clampWindow(d.length(old), d.length(updated), windowSize)
+		// Search for the first difference between the two texts
+		_, firstDiffIndex, isEnd, err := d.searchFirstDif(old, updated, windowSize)
+		if err != nil {
+			return nil, err
+		}
+		oldGeneralIndex = oldGeneralIndex + firstDiffIndex
+		found := len(edits)
+		addedContent := ""
+		deletedContent := ""
+		previousContent := ""
+		newContent := ""
+		oldAddIndex := 0
+		newAddIndex := 0
+		oldDelIndex := 0
+		newPatternIndex := 0
+		isAdded := false
+		isDel := false
+		oldModifiedIndex := 0
+		newModifiedIndex := 0
+		isModified := false
+		// The character right before the difference, shared by both texts
+		shared := rune(-1)
+		if firstDiffIndex > 0 {
+			shared = d.first(d.rest(old, firstDiffIndex-1))
+		}
+		old = d.rest(old, firstDiffIndex)
+		updated = d.rest(updated, firstDiffIndex)
+		if !isEnd {
+			// If we have differences in the following parts
+			previousContent, newContent, oldModifiedIndex, newModifiedIndex, isModified = d.searchModifiedContent(old, updated, windowSize)
+			addedContent, oldAddIndex, newAddIndex, isAdded = d.searchAddedContent(old, updated, windowSize)
+			deletedContent, oldDelIndex, newPatternIndex, isDel = d.searchDeletedContent(old, updated, 1)
+			// The windows over a run of one character all look alike, so a run
+			// grown or shrunk on one side, after which both texts go on alike, is
+			// also read as an addition or deletion at the end of the run
+			if grown := d.leadingRun(updated, shared); grown > 0 && (!isAdded || grown < newAddIndex) && d.continuesAfter(old, updated, grown) {
+				addedContent, oldAddIndex, newAddIndex, isAdded = d.content(updated, grown), 0, grown, true
+			}
+			if shrunk := d.leadingRun(old, shared); shrunk > 0 && (!isDel || shrunk < oldDelIndex) && d.continuesAfter(updated, old, shrunk) {
+				deletedContent, oldDelIndex, newPatternIndex, isDel = d.content(old, shrunk), shrunk, 0, true
+			}
+			if isModified {
+				// A modification only matches after characters that happen to repeat when an
+				// insertion or deletion no longer than it realigns the texts too
+				isAdded = isAdded && newAddIndex <= oldModifiedIndex
+				isDel = isDel && oldDelIndex <= oldModifiedIndex
+				isModified = !isAdded && !isDel
+			}
+			// When both readings realign the shorter edit wins, and an addition wins a tie
+			if isAdded && isDel {
+				isAdded = newAddIndex <= oldDelIndex
+				isDel = !isAdded
+			}
+			modifiedLen := 0
+			if isModified {
+				modifiedLen = oldModifiedIndex
+			}
+			// Nothing realigns with this window, so retry from the difference with a smaller one
+			if d.adaptive && windowSize > 1 && stalled(d.length(old), d.length(updated), modifiedLen, isModified || isAdded || isDel) {
+				windowSize /= 2
+				continue
+			}
+
+			if isModified { // If it is a modification
+				old = d.rest(old, oldModifiedIndex)
+				updated = d.rest(updated, newModifiedIndex)
+				edits = appendEdit(edits, d.editLen(oldGeneralIndex, previousContent, newContent, oldModifiedIndex, newModifiedIndex))
+				oldGeneralIndex += oldModifiedIndex
+			} else if isAdded { // If it is an added content
+				old = d.rest(old, oldAddIndex)
+				updated = d.rest(updated, newAddIndex)
+				edits = appendEdit(edits, d.editLen(oldGeneralIndex, "", addedContent, 0, newAddIndex))
+				oldGeneralIndex += oldAddIndex
+			} else if isDel { // If it is a deleted
+				old = d.rest(old, oldDelIndex)
+				updated = d.rest(updated, newPatternIndex)
+				edits = appendEdit(edits, d.editLen(oldGeneralIndex, deletedContent, "", oldDelIndex, 0))
+				oldGeneralIndex += oldDelIndex
+			} else { // end case
+				// Nothing realigns, so the rest of both texts is one replacement
+				edits = appendEdit(edits, d.edit(oldGeneralIndex, old, updated))
+				old, updated = "", ""
+			}
+		}
+
+		d.found += len(edits) - found
+		if edits, err = d.flush(edits); err != nil {
+			return nil, err
+		}
+	}
+	// A search cut short by the context may have ended the loop
+	if err := d.cancelled(0); err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// stalled reports whether a pass would consume the rest of old and updated,
+// of oldLen and updatedLen characters, as one edit because their windows never matched again: no search realigned,
+// or the modification search reached the end of both texts.
+func stalled(oldLen, updatedLen, modifiedLen int, found bool) bool {
+	if !found {
+		return true
+	}
+	return modifiedLen == oldLen && oldLen == updatedLen
+}
+
+// continuesAfter reports whether fixed and moving start with the same
+// character once the first skip characters of moving are skipped.
+func (d *differ) continuesAfter(fixed, moving string, skip int) bool {
+	rest := d.rest(moving, skip)
+	return fixed != "" && rest != "" && d.first(rest) == d.first(fixed)
+}
+
+// leadingRun returns how many times text repeats r at its start.
+func (d *differ) leadingRun(text string, r rune) int {
+	n := 0
+	if d.bytewise {
+		for n < len(text) && rune(text[n]) == r {
+			n++
+		}
+		return n
+	}
+	for _, c := range text {
+		if c != r {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// appendEdit appends edit to edits unless it carries no content.
+func appendEdit(edits []Edit, edit Edit) []Edit {
+	if edit.OldLen == 0 && edit.NewLen == 0 {
+		return edits
+	}
+	return append(edits, edit)
+}
+
+// Diff compares old against updated and returns the delta describing the
+// added, deleted and modified content. Positions in the delta are 1-based
+// rune indexes into old. A panic while comparing is returned as an error.
+func Diff(old, updated string, windowSize int) (string, error) {
+	delta, _, err := DiffFull(old, updated, windowSize)
+	return delta, err
+}
+
+// DiffWithChecksum works like Diff and starts the delta with a line holding
+// the checksum of old, so ApplyPatch refuses to apply it to any other text.
+func DiffWithChecksum(old, updated string, windowSize int) (string, error) {
+	delta, err := Diff(old, updated, windowSize)
+	if err != nil {
+		return "", err
+	}
+	return checksumPrefix + checksum(old) + "\n" + delta, nil
+}
+
+// DiffFull compares old against updated once and returns both the delta Diff
+// gives and the edits DiffEdits gives. The delta is formatted from the edits,
+// so both always describe the same differences.
+func DiffFull(old, updated string, windowSize int) (string, []Edit, error) {
+	edits, err := DiffEdits(old, updated, windowSize)
+	if err != nil {
+		return "", nil, err
+	}
+	return formatEdits(edits), edits, nil
+}
+
+// DiffEdits compares old against updated and returns the differences as
+// structured edits. Edit positions are 0-based rune indexes into old. A panic
+// while comparing is returned as an error.
+func DiffEdits(old, updated string, windowSize int) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	return newDiffer(DiffOptions{}).collectEdits(old, updated, windowSize, 0)
+}
+
+// DiffContext compares old against updated like DiffEdits, giving up with
+// the error of ctx once it is cancelled or its deadline passes, so the time
+// spent on untrusted input can be bounded. The context is checked between
+// the edits found and regularly while searching for each of them.
+func DiffContext(ctx context.Context, old, updated string, windowSize int) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d := newDiffer(DiffOptions{})
+	d.ctx = ctx
+	return d.collectEdits(old, updated, windowSize, 0)
+}
+
+// DiffWithOptions compares old against updated using the settings in opts
+// and returns the differences as structured edits. Edit positions are 0-based
+// rune indexes into old. A panic while comparing, including one of the
+// Postprocessors, is returned as an error.
+func DiffWithOptions(old, updated string, opts DiffOptions) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	if err := opts.Hash.validate(); err != nil {
+		return nil, err
+	}
+	return newDiffer(opts).diff(old, updated, opts.WindowSize)
+}
+
+// DiffWithStats works like DiffWithOptions and also returns how many hash
+// operations the comparison performed.
+func DiffWithStats(old, updated string, opts DiffOptions) (edits []Edit, stats DiffStats, err error) {
+	defer recoverPanic(&err)
+	if err := opts.Hash.validate(); err != nil {
+		return nil, DiffStats{}, err
+	}
+	d := newDiffer(opts)
+	edits, err = d.diff(old, updated, opts.WindowSize)
+	if err != nil {
+		return nil, DiffStats{}, err
+	}
+	return edits, d.stats, nil
+}
+
+// DiffLimited works like DiffWithOptions and also reports whether the
+// comparison stopped early because opts.MaxEdits edits were found. A truncated
+// result holds at most MaxEdits edits covering the start of the texts.
+func DiffLimited(old, updated string, opts DiffOptions) (edits []Edit, truncated bool, err error) {
+	defer recoverPanic(&err)
+	if err := opts.Hash.validate(); err != nil {
+		return nil, false, err
+	}
+	d := newDiffer(opts)
+	edits, err = d.diff(old, updated, opts.WindowSize)
+	if err != nil {
+		return nil, false, err
+	}
+	if d.maxEdits > 0 && len(edits) > d.maxEdits {
+		edits, d.truncated = edits[:d.maxEdits], true
+	}
+	return edits, d.truncated, nil
+}
@@ -0,0 +1,978 @@
+package textcompare
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// mustCheckString runs checkString failing the test on error.
+func mustCheckString(t *testing.T, old, updated string, windowSize int, oldGeneralIndex int) string {
+	t.Helper()
+	delta, err := checkString(old, updated, windowSize, oldGeneralIndex)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+	return delta
+}
+
+func TestSearchAddedContent(t *testing.T) {
+	// Test when there is added content at the beginning of the updated text
+	t.Run("Added content at the beginning", func(t *testing.T) {
+		oldText := "world"
+		updatedText := "hello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is added content at the end of the updated text
+	t.Run("Added content at the end", func(t *testing.T) {
+		oldText := "hello"
+		updatedText := "hello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is added content in the middle of the updated text
+	t.Run("Added content in the middle", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello there world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+	// Test when there is no added content
+	t.Run("No added content", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+func TestSearchDeletedContent(t *testing.T) {
+	// Test when there is deleted content at the beginning of the old text
+	t.Run("Deleted content at the beginning", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is deleted content at the end of the old text
+	t.Run("Deleted content at the end", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is deleted content in the middle of the old text
+	t.Run("Deleted content in the middle", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello there"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is no deleted content
+	t.Run("No deleted content", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+func TestSearchModifiedContent(t *testing.T) {
+	//Test when there is modified content at the beginning of the old text
+	t.Run("Modified content at the beginning", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "jello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is modified content at the end of the old text
+	t.Run("Modified content at the end", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello worlx"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is modified content in the middle of the old text
+	t.Run("Modified content in the middle", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello xorld"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test when there is no modified content
+	t.Run("No modified content", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello world"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+func TestUnicodeContent(t *testing.T) {
+	// Test that a single accented character is reported as one modification at its rune position
+	t.Run("Modified accented character", func(t *testing.T) {
+		oldText := "café"
+		updatedText := "cafe"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedDelta := "Start character: 4 Length: 1/1 [--- é][+++ e]\n"
+		if delta != expectedDelta {
+			t.Errorf("Test failed. Expected: %q Got: %q", expectedDelta, delta)
+		}
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test Spanish text with several multibyte characters before the difference
+	t.Run("Spanish text", func(t *testing.T) {
+		oldText := "el niño comió"
+		updatedText := "el nino comió"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedDelta := "Start character: 6 Length: 1/1 [--- ñ][+++ n]\n"
+		if delta != expectedDelta {
+			t.Errorf("Test failed. Expected: %q Got: %q", expectedDelta, delta)
+		}
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test that an emoji is treated as a single character
+	t.Run("Modified emoji", func(t *testing.T) {
+		oldText := "hola 👋 mundo"
+		updatedText := "hola 🌍 mundo"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedDelta := "Start character: 6 Length: 1/1 [--- 👋][+++ 🌍]\n"
+		if delta != expectedDelta {
+			t.Errorf("Test failed. Expected: %q Got: %q", expectedDelta, delta)
+		}
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+
+	// Test added content after multibyte characters
+	t.Run("Added content after accented text", func(t *testing.T) {
+		oldText := "añadir"
+		updatedText := "añadir más"
+		windowSize := 2
+		delta := mustCheckString(t, oldText, updatedText, windowSize, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+
+func TestDiff(t *testing.T) {
+	// Test the exported entry point reconstructs the updated text
+	t.Run("Diff and ReplaceDelta round trip", func(t *testing.T) {
+		oldText := "hello world"
+		updatedText := "hello there world"
+		windowSize := 2
+		delta, err := Diff(oldText, updatedText, windowSize)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+
+func TestSearchFirstDifErrors(t *testing.T) {
+	// Test that invalid input is reported instead of being silently ignored
+	tests := []struct {
+		name       string
+		text1      string
+		text2      string
+		windowSize int
+	}{
+		{"Zero window", "hello", "jello", 0},
+		{"Negative window", "hello", "jello", -1},
+		{"Empty old text", "", "hello", 1},
+		{"Empty updated text", "hello", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := SearchFirstDif(tt.text1, tt.text2, tt.windowSize)
+			if _, ok := err.(*CustomError); !ok {
+				t.Errorf("Test failed. Expected a *CustomError Got: %v", err)
+			}
+		})
+	}
+
+	// Test that Diff propagates the error for a zero window
+	t.Run("Diff with zero window", func(t *testing.T) {
+		if _, err := Diff("hello", "jello", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+		if _, err := DiffEdits("", "jello", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestSearchFirstDifOversizedWindow(t *testing.T) {
+	// Test that windows larger than both texts are clamped instead of failing
+	tests := []struct {
+		name       string
+		text1      string
+		text2      string
+		windowSize int
+		equalText  string
+		index      int
+		isEnd      bool
+	}{
+		{"Difference in the middle", "hello", "helpo", 6, "hel", 3, false},
+		{"Difference at the start", "hello", "jello", 50, "", 0, false},
+		{"Identical texts", "hello", "hello", 6, "hello", 5, true},
+		{"Shorter text is a prefix", "hel", "hello", 10, "hel", 3, true},
+		{"Multibyte characters", "año", "añx", 8, "añ", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equalText, index, isEnd, err := SearchFirstDif(tt.text1, tt.text2, tt.windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if equalText != tt.equalText || index != tt.index || isEnd != tt.isEnd {
+				t.Errorf("Test failed. Expected: %q %d %t Got: %q %d %t", tt.equalText, tt.index, tt.isEnd, equalText, index, isEnd)
+			}
+		})
+	}
+}
+
+func TestClampWindow(t *testing.T) {
+	// Test that the window shrinks to the shortest text and never below 1
+	t.Run("Clamped sizes", func(t *testing.T) {
+		cases := [][4]int{{10, 20, 4, 4}, {3, 20, 4, 3}, {20, 2, 4, 2}, {0, 5, 4, 1}, {5, 5, 5, 5}}
+		for _, c := range cases {
+			if got := clampWindow(c[0], c[1], c[2]); got != c[3] {
+				t.Errorf("Test failed. Lengths %d and %d window %d Expected: %d Got: %d", c[0], c[1], c[2], c[3], got)
+			}
+		}
+	})
+
+	// Test that every entry point gives the same result with an oversized window as with the clamped one
+	t.Run("Uniform clamping", func(t *testing.T) {
+		oldText, updatedText := "abc", "abXcdefgh"
+		results := func(windowSize int) []interface{} {
+			edits, _ := DiffEdits(oldText, updatedText, windowSize)
+			delta, _ := Diff(oldText, updatedText, windowSize)
+			withOptions, _ := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: windowSize})
+			ranged, _ := DiffRange(oldText, updatedText, 0, 3, 0, 9, windowSize)
+			byteEdits, _ := DiffBytes([]byte(oldText), []byte(updatedText), windowSize)
+			index, equal, _ := FirstDifference(oldText, updatedText, windowSize)
+			equalText, firstDif, isEnd, _ := SearchFirstDif(oldText, updatedText, windowSize)
+			return []interface{}{edits, delta, withOptions, ranged, byteEdits, index, equal, equalText, firstDif, isEnd}
+		}
+		clamped := results(3)
+		for _, windowSize := range []int{4, 8} {
+			if got := results(windowSize); !reflect.DeepEqual(got, clamped) {
+				t.Errorf("Test failed. Window %d Expected: %v Got: %v", windowSize, clamped, got)
+			}
+		}
+	})
+
+	// Test that a logger is warned when the window is clamped
+	t.Run("Warning", func(t *testing.T) {
+		var buf strings.Builder
+		logger := log.New(&buf, "", 0)
+		if _, err := DiffWithOptions("abc", "abXcdefgh", DiffOptions{WindowSize: 8, Logger: logger}); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := "window size 8 exceeds the texts (3 and 9 characters), clamped to 3\n"
+		if buf.String() != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, buf.String())
+		}
+		buf.Reset()
+		if _, err := DiffWithOptions("abc", "abXcdefgh", DiffOptions{WindowSize: 2, Logger: logger}); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Test failed. Expected: no warning Got: %q", buf.String())
+		}
+	})
+}
+
+func TestDiffFull(t *testing.T) {
+	pairs := [][2]string{
+		{"hello world", "hello brave new world"},
+		{"I like cats", "I love dogs"},
+		{"héllo\nwörld]", "hello\nworld"},
+		{"same", "same"},
+		{"", "added"},
+	}
+
+	// Test that the delta is the formatting of the edits and matches Diff
+	t.Run("Consistent", func(t *testing.T) {
+		for _, pair := range pairs {
+			delta, edits, err := DiffFull(pair[0], pair[1], 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if expected := formatEdits(edits); delta != expected {
+				t.Errorf("Test failed. Expected: %s Got: %s", expected, delta)
+			}
+			if expected, _ := Diff(pair[0], pair[1], 2); delta != expected {
+				t.Errorf("Test failed. Expected: %s Got: %s", expected, delta)
+			}
+			if expected, _ := DiffEdits(pair[0], pair[1], 2); !reflect.DeepEqual(edits, expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+			}
+		}
+	})
+
+	// Test that invalid windows are rejected
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, _, err := DiffFull("a", "b", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	// Test that an index out of range in the engine becomes an error
+	t.Run("Index out of range", func(t *testing.T) {
+		logged.Reset()
+		compare := func(runes []rune) (err error) {
+			defer recoverPanic(&err)
+			_ = runes[len(runes)]
+			return nil
+		}
+		var customErr *CustomError
+		if err := compare([]rune("abc")); !errors.As(err, &customErr) {
+			t.Errorf("Test failed. Expected: a CustomError Got: %v", err)
+		}
+		if !strings.Contains(logged.String(), "index out of range") {
+			t.Errorf("Test failed. Expected: the panic logged Got: %s", logged.String())
+		}
+	})
+
+	// Test that a panicking postprocessor fails the comparison instead of the program
+	t.Run("Postprocessor", func(t *testing.T) {
+		logged.Reset()
+		explode := func(edits []Edit, old, updated string) []Edit { panic("postprocessor exploded") }
+		edits, err := DiffWithOptions("hello world", "hello xorld", DiffOptions{WindowSize: 2, Postprocessors: []Postprocessor{explode}})
+		if err == nil || edits != nil || !strings.Contains(err.Error(), "postprocessor exploded") {
+			t.Errorf("Test failed. Expected: an error Got: %+v %v", edits, err)
+		}
+		if !strings.Contains(logged.String(), "postprocessor exploded") {
+			t.Errorf("Test failed. Expected: the panic logged Got: %s", logged.String())
+		}
+	})
+
+	// Test that the entry points beyond DiffEdits and DiffWithOptions recover too
+	t.Run("Other entry points", func(t *testing.T) {
+		logged.Reset()
+		explode := func(edits []Edit, old, updated string) []Edit { panic("postprocessor exploded") }
+		c, err := NewComparer(DiffOptions{WindowSize: 2, Postprocessors: []Postprocessor{explode}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if _, err := c.Diff("hello world", "hello xorld"); err == nil || !strings.Contains(err.Error(), "postprocessor exploded") {
+			t.Errorf("Test failed. Expected: an error from Comparer.Diff Got: %v", err)
+		}
+		if _, _, err := DiffWithStats("hello world", "hello xorld", DiffOptions{WindowSize: 2, Postprocessors: []Postprocessor{explode}}); err == nil {
+			t.Errorf("Test failed. Expected: an error from DiffWithStats Got: nil")
+		}
+		err = DiffStream("hello world", "hello xorld", 2, func(Edit) error { panic("emit exploded") })
+		if err == nil || !strings.Contains(err.Error(), "emit exploded") {
+			t.Errorf("Test failed. Expected: an error from DiffStream Got: %v", err)
+		}
+		if !strings.Contains(logged.String(), "emit exploded") {
+			t.Errorf("Test failed. Expected: the panic logged Got: %s", logged.String())
+		}
+	})
+
+	// Test that comparisons without a panic are not affected
+	t.Run("No panic", func(t *testing.T) {
+		logged.Reset()
+		if _, err := DiffEdits("hello world", "hello xorld", 2); err != nil || logged.Len() != 0 {
+			t.Errorf("Test failed. Expected: no error Got: %v %s", err, logged.String())
+		}
+	})
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected string
+	}{
+		{"Disjoint texts", "abc", "xyz", ""},
+		{"Partially shared", "hello world", "hello there", "hello "},
+		{"Fully shared", "hello", "hello", "hello"},
+		{"One text prefix of the other", "hello", "hello world", "hello"},
+		{"Empty text", "", "hello", ""},
+		{"Multibyte characters", "año", "añx", "añ"},
+		{"Same first byte", "é", "è", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommonPrefix(tt.a, tt.b); got != tt.expected {
+				t.Errorf("Test failed. Expected: %q Got: %q", tt.expected, got)
+			}
+		})
+	}
+
+	// Test that the prefix is the equal text found by SearchFirstDif
+	t.Run("Matches SearchFirstDif", func(t *testing.T) {
+		for _, pair := range [][2]string{{"hello world", "hello there"}, {"año nuevo", "año viejo"}, {"abc", "xyz"}} {
+			equalText, _, _, err := SearchFirstDif(pair[0], pair[1], 3)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if got := CommonPrefix(pair[0], pair[1]); got != equalText {
+				t.Errorf("Test failed. Expected: %q Got: %q", equalText, got)
+			}
+		}
+	})
+}
+
+func TestCommonPrefixSuffix(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         string
+		b         string
+		prefixLen int
+		suffixLen int
+	}{
+		{"Identical texts", "hello", "hello", 5, 0},
+		{"Empty text", "", "hello", 0, 0},
+		{"Change in the middle", "hello world", "hello there world", 6, 5},
+		{"Prefix does not overlap suffix", "aa", "aaa", 2, 0},
+		{"Multibyte characters", "año nuevo", "año viejo", 4, 1},
+		{"Nothing in common", "abc", "xyz", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixLen, suffixLen := CommonPrefixSuffix(tt.a, tt.b)
+			if prefixLen != tt.prefixLen || suffixLen != tt.suffixLen {
+				t.Errorf("Test failed. Expected: %d %d Got: %d %d", tt.prefixLen, tt.suffixLen, prefixLen, suffixLen)
+			}
+		})
+	}
+
+	// Test that the edits of a change in the middle of a long text stay in it
+	t.Run("Trimmed search", func(t *testing.T) {
+		shared := strings.Repeat("lorem ipsum ", 50)
+		old, updated := shared+"dolor"+shared, shared+"amet"+shared
+		edits, err := DiffEdits(old, updated, 3)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		for _, edit := range edits {
+			if edit.Start < len(shared) || edit.Start+len(edit.Old) > len(shared)+len("dolor") {
+				t.Errorf("Test failed. Expected: edits inside [%d, %d) Got: %+v", len(shared), len(shared)+len("dolor"), edit)
+			}
+		}
+		if got := applyEdits(old, edits); got != updated {
+			t.Errorf("Test failed. Expected: %s Got: %s", updated, got)
+		}
+	})
+}
+
+func BenchmarkDiffMiddleChange(b *testing.B) {
+	shared := strings.Repeat("lorem ipsum dolor sit amet ", 200)
+	old := shared + "consectetur" + shared
+	updated := shared + "adipiscing" + shared
+	for i := 0; i < b.N; i++ {
+		DiffEdits(old, updated, 4)
+	}
+}
+
+func BenchmarkDiffIdentical(b *testing.B) {
+	old := strings.Repeat("lorem ipsum dolor sit amet ", 1<<15)
+	updated := strings.Clone(old)
+	for i := 0; i < b.N; i++ {
+		DiffEdits(old, updated, 4)
+	}
+}
+
+func TestIdenticalTexts(t *testing.T) {
+	// Test that identical texts give no edits and an empty delta
+	for _, text := range []string{"", "a", "hello world", "año nuevo"} {
+		edits, stats, err := DiffWithStats(text, strings.Clone(text), DiffOptions{WindowSize: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if edits != nil || stats != (DiffStats{}) {
+			t.Errorf("Test failed. %q Expected: no edits and no hashing Got: %+v %+v", text, edits, stats)
+		}
+		delta := mustCheckString(t, text, text, 2, 0)
+		if delta != "" {
+			t.Errorf("Test failed. Expected: an empty delta Got: %q", delta)
+		}
+		if got := ReplaceDelta(text, delta); got != text {
+			t.Errorf("Test failed. Expected: %s Got: %s", text, got)
+		}
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	// Test the divergence point for identical, early and late differences
+	tests := []struct {
+		name       string
+		oldText    string
+		updated    string
+		windowSize int
+		index      int
+		equal      bool
+	}{
+		{"Identical texts", "hello world", "hello world", 4, -1, true},
+		{"Empty texts", "", "", 2, -1, true},
+		{"Early divergence", "hello world", "jello world", 4, 0, false},
+		{"Late divergence", "hello world", "hello worlx", 4, 10, false},
+		{"Middle divergence", "the quick brown fox", "the quack brown fox", 3, 6, false},
+		{"Prefix of the other", "hello", "hello world", 2, 5, false},
+		{"Empty old text", "", "hello", 2, 0, false},
+		{"Multibyte characters", "año nuevo", "año viejo", 2, 4, false},
+		{"Window larger than the texts", "abc", "abd", 10, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, equal, err := FirstDifference(tt.oldText, tt.updated, tt.windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if index != tt.index || equal != tt.equal {
+				t.Errorf("Test failed. Expected: %d %t Got: %d %t", tt.index, tt.equal, index, equal)
+			}
+		})
+	}
+
+	// Test that invalid window sizes are rejected
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, _, err := FirstDifference("a", "b", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestFullReplacement(t *testing.T) {
+	// Test that texts sharing no common window give a single modification
+	tests := [][2]string{{"abc", "xyz"}, {"12345", "abcde"}, {"abc", "wxyz"}, {"hello", "HELLO WORLD"}}
+	for _, tt := range tests {
+		for windowSize := 1; windowSize <= 3; windowSize++ {
+			edits, err := DiffEdits(tt[0], tt[1], windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			expected := []Edit{newEdit(0, tt[0], tt[1])}
+			if !reflect.DeepEqual(edits, expected) {
+				t.Errorf("Test failed. %q Window %d Expected: %+v Got: %+v", tt, windowSize, expected, edits)
+			}
+		}
+	}
+
+	// Test that a replaced middle is reported as one modification
+	t.Run("Replaced middle", func(t *testing.T) {
+		edits, err := DiffEdits("one two three", "one 2 three", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 4, NewStart: 4, Old: "two", New: "2", OldLen: 3, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
+
+func TestManyEdits(t *testing.T) {
+	// Test that thousands of sequential edits are found without exhausting the stack
+	oldText := strings.Repeat("ab", 2000)
+	updatedText := strings.Repeat("ax", 2000)
+	edits, err := DiffEdits(oldText, updatedText, 1)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+	if len(edits) != 2000 {
+		t.Errorf("Test failed. Expected: 2000 edits Got: %d", len(edits))
+	}
+	if got := applyEdits(oldText, edits); got != updatedText {
+		t.Errorf("Test failed. Expected: the updated text Got: %d characters", len(got))
+	}
+}
+
+func TestTieBreak(t *testing.T) {
+	// Test that an addition and a deletion of the same length resolve to the addition
+	t.Run("Tie prefers the addition", func(t *testing.T) {
+		delta := mustCheckString(t, "ab cd.", "cd ab cd!", 2, 0)
+		expected := "Start character: 1 Length: 0/3 [+++ cd ]\nStart character: 6 Length: 1/1 [--- .][+++ !]\n"
+		if delta != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, delta)
+		}
+	})
+
+	// Test that the shorter of an addition and a deletion that both realign is chosen
+	t.Run("Shorter edit wins", func(t *testing.T) {
+		edits, err := DiffEdits("Xhello world.", "hello Xhello world!", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 || edits[0].Op != Deleted || edits[0].Old != "X" {
+			t.Errorf("Test failed. Expected: the deletion of X first Got: %+v", edits)
+		}
+		if got := applyEdits("Xhello world.", edits); got != "hello Xhello world!" {
+			t.Errorf("Test failed. Expected: hello Xhello world! Got: %s", got)
+		}
+	})
+
+	// Test that repeated comparisons of an ambiguous input give the same delta
+	t.Run("Stable output", func(t *testing.T) {
+		first := mustCheckString(t, "ab cd.", "cd ab cd!", 2, 0)
+		for i := 0; i < 100; i++ {
+			if got := mustCheckString(t, "ab cd.", "cd ab cd!", 2, 0); got != first {
+				t.Fatalf("Test failed. Expected: %q Got: %q", first, got)
+			}
+		}
+	})
+}
+
+func TestPureInsertion(t *testing.T) {
+	// Test that an insertion followed by the character it precedes is not read as a replacement
+	tests := []struct {
+		name     string
+		old      string
+		updated  string
+		expected []Edit
+	}{
+		{"Insertion before a repeated character", "abXcd", "abYXcd", []Edit{newEdit(2, "", "Y")}},
+		{"Insertion before repeated characters", "aaabc", "Yaaabd", []Edit{newEdit(0, "", "Y"), newEdit(4, "c", "d")}},
+		{"Deletion before repeated characters", "Yaaabc", "aaabd", []Edit{newEdit(0, "Y", ""), newEdit(5, "c", "d")}},
+		{"Replacement kept", "abXcdef!", "abYcdef?", []Edit{newEdit(2, "X", "Y"), newEdit(7, "!", "?")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 3; windowSize++ {
+				edits, err := DiffEdits(tt.old, tt.updated, windowSize)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(edits, setNewStarts(tt.expected)) {
+					t.Errorf("Test failed. Window %d Expected: %+v Got: %+v", windowSize, tt.expected, edits)
+				}
+			}
+		})
+	}
+}
+
+func TestRepeatedRuns(t *testing.T) {
+	// Test that a run of one character grown or shrunk is a minimal edit at the end of the run
+	tests := []struct {
+		name     string
+		old      string
+		updated  string
+		expected []Edit
+	}{
+		{"Run grown", "aaaa", "aaaaa", []Edit{newEdit(4, "", "a")}},
+		{"Run shrunk", "aaaaa", "aaaa", []Edit{newEdit(4, "a", "")}},
+		{"Run grown in the middle", "baaac", "baaaac", []Edit{newEdit(4, "", "a")}},
+		{"Run shrunk in the middle", "baaaac", "baaac", []Edit{newEdit(4, "a", "")}},
+		{"Run grown between changes", "1aaab2", "3aaaab4", []Edit{newEdit(0, "1", "3"), newEdit(4, "", "a"), newEdit(5, "2", "4")}},
+		{"Run shrunk between changes", "1aaaab2", "3aaab4", []Edit{newEdit(0, "1", "3"), newEdit(4, "a", ""), newEdit(6, "2", "4")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 3; windowSize++ {
+				edits, err := DiffEdits(tt.old, tt.updated, windowSize)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(edits, setNewStarts(tt.expected)) {
+					t.Errorf("Test failed. Window %d Expected: %+v Got: %+v", windowSize, tt.expected, edits)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchModifiedScan(t *testing.T) {
+	// Test the two-pointer scan from the first difference of both texts
+	tests := []struct {
+		name       string
+		text1      string
+		text2      string
+		windowSize int
+		previous   string
+		next       string
+		index      int
+	}{
+		{"Modified at the start", "hello world", "jello world", 3, "h", "j", 1},
+		{"Modified run before shared content", "XYdefgh", "PQdefgh", 3, "XY", "PQ", 2},
+		{"Realigned on the last character", "XYz", "PQz", 2, "XY", "PQ", 2},
+		{"Adjacent to EOF", "xa", "ya", 3, "x", "y", 1},
+		{"Multibyte characters", "ñandú", "nandú", 2, "ñ", "n", 1},
+	}
+	d := newDiffer(DiffOptions{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, next, indexOld, indexNew, found := d.searchModifiedContent(tt.text1, tt.text2, tt.windowSize)
+			if previous != tt.previous || next != tt.next {
+				t.Errorf("Test failed. Expected: %q %q Got: %q %q", tt.previous, tt.next, previous, next)
+			}
+			if indexOld != tt.index || indexNew != tt.index || !found {
+				t.Errorf("Test failed. Expected: %d %d true Got: %d %d %t", tt.index, tt.index, indexOld, indexNew, found)
+			}
+		})
+	}
+
+	// Test that texts of different lengths that never realign report no modification
+	t.Run("Shortest text ends first", func(t *testing.T) {
+		if _, _, _, _, found := d.searchModifiedContent("xyz", "abcdef", 2); found {
+			t.Errorf("Test failed. Expected: no modification Got: %t", found)
+		}
+	})
+
+	// Test the resulting edits for modifications at each position
+	edits := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{"Start", "hello world", "jello world", []Edit{{Op: Modified, Start: 0, NewStart: 0, Old: "h", New: "j", OldLen: 1, NewLen: 1}}},
+		{"Middle", "hello world", "hello wOrld", []Edit{{Op: Modified, Start: 7, NewStart: 7, Old: "o", New: "O", OldLen: 1, NewLen: 1}}},
+		{"End", "hello world", "hello worlx", []Edit{{Op: Modified, Start: 10, NewStart: 10, Old: "d", New: "x", OldLen: 1, NewLen: 1}}},
+		{"Adjacent to EOF", "hello world", "hello woRLd", []Edit{{Op: Modified, Start: 8, NewStart: 8, Old: "rl", New: "RL", OldLen: 2, NewLen: 2}}},
+		{"Start and end", "hello world", "jello worlx", []Edit{
+			{Op: Modified, Start: 0, NewStart: 0, Old: "h", New: "j", OldLen: 1, NewLen: 1},
+			{Op: Modified, Start: 10, NewStart: 10, Old: "d", New: "x", OldLen: 1, NewLen: 1},
+		}},
+	}
+	for _, tt := range edits {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 4; windowSize++ {
+				got, err := DiffEdits(tt.oldText, tt.updated, windowSize)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.expected) {
+					t.Errorf("Test failed. Window %d Expected: %+v Got: %+v", windowSize, tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestTrailingNewline(t *testing.T) {
+	// Test that adding or removing the final newline is a single edit at the end
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{"Newline removed", "hello\n", "hello", []Edit{{Op: Deleted, Start: 5, NewStart: 5, Old: "\n", OldLen: 1, WhitespaceOnly: true}}},
+		{"Newline added", "hello", "hello\n", []Edit{{Op: Added, Start: 5, NewStart: 5, New: "\n", NewLen: 1, WhitespaceOnly: true}}},
+		{"Newline removed after lines", "a\nb\n", "a\nb", []Edit{{Op: Deleted, Start: 3, NewStart: 3, Old: "\n", OldLen: 1, WhitespaceOnly: true}}},
+		{"Only a newline", "\n", "", []Edit{{Op: Deleted, Start: 0, NewStart: 0, Old: "\n", OldLen: 1, WhitespaceOnly: true}}},
+	}
+	d := newDiffer(DiffOptions{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for windowSize := 1; windowSize <= 3; windowSize++ {
+				edits, err := DiffEdits(tt.oldText, tt.updated, windowSize)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(edits, tt.expected) {
+					t.Errorf("Test failed. Window %d Expected: %+v Got: %+v", windowSize, tt.expected, edits)
+				}
+				// The search without trimming the shared start finds it too
+				edits, err = d.collectMiddleEdits(tt.oldText, tt.updated, windowSize, 0)
+				if err != nil {
+					t.Fatalf("Test failed. Unexpected error: %v", err)
+				}
+				if got := applyEdits(tt.oldText, edits); got != tt.updated || len(edits) != 1 {
+					t.Errorf("Test failed. Window %d Expected: one edit giving %q Got: %+v", windowSize, tt.updated, edits)
+				}
+			}
+		})
+	}
+
+	// Test that the search helpers stop at the end of a one character tail
+	t.Run("Search helpers", func(t *testing.T) {
+		added, _, indexNew, found := d.searchAddedContent("\n", "x\n", 2)
+		if added != "x" || indexNew != 1 || !found {
+			t.Errorf("Test failed. Expected: %q 1 true Got: %q %d %t", "x", added, indexNew, found)
+		}
+		deleted, indexOld, _, found := d.searchDeletedContent("x\n", "\n", 2)
+		if deleted != "x" || indexOld != 1 || !found {
+			t.Errorf("Test failed. Expected: %q 1 true Got: %q %d %t", "x", deleted, indexOld, found)
+		}
+		added, _, indexNew, found = d.searchAddedContent("x\n", "y", 2)
+		if added != "y" || indexNew != 1 || found {
+			t.Errorf("Test failed. Expected: %q 1 false Got: %q %d %t", "y", added, indexNew, found)
+		}
+	})
+}
+
+func FuzzDiffApply(f *testing.F) {
+	seeds := []struct {
+		oldText    string
+		updated    string
+		windowSize int
+	}{
+		{"world", "hello world", 2},
+		{"hello", "hello world", 2},
+		{"hello world", "hello there world", 2},
+		{"hello world", "hello", 2},
+		{"hello world", "hello xorld", 2},
+		{"the cat sat on the mat", "the tiger lay on mat", 3},
+		{"AFAAVAF", "FAVFFVV", 2},
+		{"año nuevo", "año_nuevo", 2},
+		{"hello worl", "hello word", 1},
+		{"one two three", "one 2 three", 1},
+	}
+	for _, seed := range seeds {
+		f.Add(seed.oldText, seed.updated, seed.windowSize)
+	}
+	f.Fuzz(func(t *testing.T, oldText, updatedText string, windowSize int) {
+		if windowSize < 1 || windowSize > 64 {
+			t.Skip("invalid window size")
+		}
+		if !utf8.ValidString(oldText) || !utf8.ValidString(updatedText) {
+			t.Skip("texts are compared as runes")
+		}
+		delta, err := Diff(oldText, updatedText, windowSize)
+		if err != nil {
+			t.Skip("window size rejected:", err)
+		}
+		got, err := ApplyPatch(oldText, delta)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v (delta %q)", err, delta)
+		}
+		if got != updatedText {
+			t.Errorf("Test failed. Expected: %q Got: %q (delta %q)", updatedText, got, delta)
+		}
+	})
+}
+
+// expiringContext is a context cancelled after its error is checked a given
+// number of times, to cancel a comparison at a deterministic point.
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+func (c *expiringContext) Err() error {
+	if c.checks <= 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestDiffContext(t *testing.T) {
+	old, updated := strings.Repeat("lorem ipsum dolor sit amet ", 200), strings.Repeat("lorem ipsum dolor sat amet ", 200)
+
+	// Test that an already cancelled context returns promptly with its error
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		start := time.Now()
+		edits, err := DiffContext(ctx, old, updated, 4)
+		if err != context.Canceled || edits != nil {
+			t.Errorf("Test failed. Expected: %v Got: %+v %v", context.Canceled, edits, err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("Test failed. Expected: a prompt return Got: %v", elapsed)
+		}
+	})
+
+	// Test that a context cancelled during the comparison stops it
+	t.Run("Cancelled during the comparison", func(t *testing.T) {
+		ctx := &expiringContext{Context: context.Background(), checks: 5}
+		if _, err := DiffContext(ctx, old, updated, 4); err != context.Canceled {
+			t.Errorf("Test failed. Expected: %v Got: %v", context.Canceled, err)
+		}
+	})
+
+	// Test that a live context gives the same edits as DiffEdits
+	t.Run("Live context", func(t *testing.T) {
+		expected, _ := DiffEdits(old, updated, 4)
+		edits, err := DiffContext(context.Background(), old, updated, 4)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %d edits Got: %d", len(expected), len(edits))
+		}
+	})
+}
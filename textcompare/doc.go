@@ -0,0 +1,413 @@
+/*
+Package textcompare performs text comparison between two strings, identifying added, deleted, and modified content. It utilizes a rolling hash algorithm for efficient text search.
+
+Texts are compared rune by rune, so every position reported by the package counts
+runes rather than bytes.
+
+The TextSearch struct represents a search context for sliding window hashing.
+
+The CustomError struct defines a custom error type for handling errors. Every exported function that compares texts and returns an error, from Diff, DiffEdits and DiffWithOptions to DiffStream, DiffReaders, DiffBytes and the Comparer and IncrementalDiff methods, recovers from a panic of the engine or of the callbacks it is given, logs it and returns it as a CustomError instead of crashing the program.
+
+The Edit struct describes a single added, deleted or modified change, identified by its OpKind.
+
+The Postprocessor function type rewrites the edits of a comparison; DiffOptions.Postprocessors runs a pipeline of them in order after the texts are compared.
+
+The following functions are implemented:
+
+1. GetWindowString:
+  - Parameters: None
+  - Results: Returns the rest of the text from the current window.
+  - Description: Returns the text from the start of the current window to the end of the buffer.
+
+2. CurrentWindow:
+  - Parameters: None
+  - Results: Returns the current window of text.
+  - Description: Returns exactly the window of text being analyzed, clamped at the end of the buffer.
+
+3. SuggestWindowSize:
+  - Parameters: old (string), updated (string)
+  - Results: Window size (int)
+  - Description: Suggests a window size proportional to the length of the shortest text, between MinSuggestedWindow and MaxSuggestedWindow.
+
+4. Slide:
+  - Parameters: None
+  - Results: Returns a custom error, the updated hash, and the current window of text.
+  - Description: Slides the window to calculate the hash of the next text segment.
+
+5. GetHash:
+  - Parameters: None
+  - Results: Returns the current hash value.
+  - Description: Retrieves the current hash value of the text.
+
+6. CreateBuffer:
+  - Parameters: input (string), windowSize (int)
+  - Results: error
+  - Description: Initializes the text buffer with a specific window size.
+
+7. CreateBufferWithConfig:
+  - Parameters: input (string), windowSize (int), cfg (HashConfig)
+  - Results: error
+  - Description: Initializes the text buffer like CreateBuffer, hashed with the prime and base of cfg instead of DefaultHashConfig.
+
+8. SetStart:
+  - Parameters: index (int), window (int)
+  - Results: error
+  - Description: Sets the starting point of the window for hashing.
+
+9. ComputeHash:
+  - Parameters: s (string), prime (int), base (int)
+  - Results: Hash value (int)
+  - Description: Computes the polynomial hash of a string from scratch, the value a TextSearch holds for a window covering it.
+
+10. CommonPrefix:
+  - Parameters: a (string), b (string)
+  - Results: Shared leading text (string)
+  - Description: Returns the leading text shared by both texts, the equal text SearchFirstDif reports, by comparing them directly.
+
+11. CommonPrefixSuffix:
+  - Parameters: a (string), b (string)
+  - Results: Prefix length (int), suffix length (int)
+  - Description: Returns the length in runes of the common prefix and of the common suffix of two texts. Comparisons trim both before searching the differing middle.
+
+12. SearchFirstDif:
+  - Parameters: text1 (string), text2 (string), windowSize (int)
+  - Results: Equal text until first difference, index of first difference, boolean indicating completion, error
+  - Description: Searches for the first difference between two texts.
+
+13. FirstDifference:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Index of the first difference (int), whether the texts are equal (bool), error
+  - Description: Reports where two texts first diverge without computing the edits, returning -1 and true for equal texts.
+
+14. Diff:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Delta string, error
+  - Description: Compares two texts and returns the delta describing their differences.
+
+15. DiffWithChecksum:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Delta string, error
+  - Description: Compares two texts like Diff and starts the delta with the checksum of the old text, so applying it to any other text fails.
+
+16. DiffEdits:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Slice of Edit values, error
+  - Description: Compares two texts and returns the differences as structured edits holding their position in both texts.
+
+17. DiffFull:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Delta string, slice of Edit values, error
+  - Description: Compares two texts once and returns both the delta and the structured edits, the delta being formatted from the edits.
+
+18. DiffBytes:
+  - Parameters: old ([]byte), updated ([]byte), windowSize (int)
+  - Results: Slice of Edit values, error
+  - Description: Compares two byte slices byte by byte, reporting byte offsets and raw byte content, so the data does not need to be valid UTF-8. The slices are hashed in place, without being converted to strings.
+
+19. DiffBytesHex:
+  - Parameters: old ([]byte), updated ([]byte), windowSize (int)
+  - Results: Slice of Edit values, error
+  - Description: Compares two byte slices like DiffBytes, hex encoding the content of every edit so non-printable bytes are visible.
+
+20. DiffContext:
+  - Parameters: ctx (context.Context), old (string), updated (string), windowSize (int)
+  - Results: Edits ([]Edit), error
+  - Description: Compares two texts like DiffEdits, returning the error of the context once it is cancelled or its deadline passes.
+
+21. DiffWithOptions:
+  - Parameters: old (string), updated (string), opts (DiffOptions)
+  - Results: Slice of Edit values, error
+  - Description: Compares two texts with the window size, hash parameters, algorithm and normalizations (whitespace, line endings, punctuation) given in opts.
+    Edits found on normalized texts are reported with their original positions and content.
+
+22. DiffRange:
+  - Parameters: old (string), updated (string), oldStart (int), oldEnd (int), newStart (int), newEnd (int), windowSize (int)
+  - Results: Slice of Edit values, error
+  - Description: Compares only the given rune ranges of both texts, reporting edit positions in the coordinates of the whole texts.
+
+23. DiffWithStats:
+  - Parameters: old (string), updated (string), opts (DiffOptions)
+  - Results: Slice of Edit values, DiffStats, error
+  - Description: Compares two texts like DiffWithOptions and also returns how many window slides and fresh hash computations the comparison performed, and how many hash collisions it had to confirm. With opts.StrictHash a collision fails the comparison instead.
+
+24. DiffLimited:
+  - Parameters: old (string), updated (string), opts (DiffOptions)
+  - Results: Slice of Edit values, truncated (bool), error
+  - Description: Compares two texts like DiffWithOptions, stopping once opts.MaxEdits edits are found and reporting whether the result was truncated.
+
+25. DiffMinimal:
+  - Parameters: old (string), updated (string)
+  - Results: Slice of Edit values
+  - Description: Compares two texts with a shortest edit script (Myers' algorithm), reporting as few changed characters as possible. It can also be selected with the Algorithm option.
+
+26. NewIncrementalDiff:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: *IncrementalDiff, error
+  - Description: Starts a comparison whose updated text can grow with Append, which only compares again the content after the start shared by both texts.
+
+27. IncrementalDiff.Append:
+  - Parameters: content (string)
+  - Results: Slice of Edit values, error
+  - Description: Adds content to the end of the updated text and returns the edits between old and the whole updated text.
+
+28. IncrementalDiff.Edits:
+  - Parameters: None
+  - Results: Slice of Edit values
+  - Description: Returns the edits between old and the current updated text.
+
+29. IncrementalDiff.Updated:
+  - Parameters: None
+  - Results: Updated text (string)
+  - Description: Returns the current updated text.
+
+30. DiffReaders:
+  - Parameters: old (io.Reader), updated (io.Reader), windowSize (int)
+  - Results: Slice of Edit values, error
+  - Description: Compares the content of two readers buffering only a bounded chunk of each.
+
+31. DiffBatch:
+  - Parameters: pairs ([]Pair), windowSize (int)
+  - Results: Slice of Result values
+  - Description: Compares every pair concurrently on a pool of runtime.NumCPU() workers, keeping the results in the order of pairs.
+
+32. DiffLines:
+  - Parameters: old ([]string), updated ([]string)
+  - Results: Slice of Edit values
+  - Description: Compares two documents treating every line as a single unit, with positions and lengths counted in lines.
+
+33. DiffWords:
+  - Parameters: old (string), updated (string)
+  - Results: Slice of Edit values
+  - Description: Compares two texts treating every word and whitespace run as a single unit.
+
+34. DiffSentences:
+  - Parameters: old (string), updated (string)
+  - Results: Slice of Edit values
+  - Description: Compares two texts sentence by sentence, a sentence ending after ".", "!" or "?" followed by whitespace, and reports added, removed and changed sentences at sentence boundaries.
+
+35. DiffGraphemes:
+  - Parameters: old (string), updated (string)
+  - Results: Slice of Edit values
+  - Description: Compares two texts treating every grapheme cluster, such as an accented character or an emoji sequence, as a single unit, with positions counted in clusters.
+
+36. GraphemeCount:
+  - Parameters: text (string)
+  - Results: Number of grapheme clusters (int)
+  - Description: Counts the grapheme clusters of a text, the unit of the positions reported by DiffGraphemes.
+
+37. DiffTokens:
+  - Parameters: old ([]string), updated ([]string)
+  - Results: Slice of Edit values
+  - Description: Compares two token sequences treating every token as an atomic unit, with positions as rune indexes into the joined old tokens.
+
+38. DiffSplit:
+  - Parameters: old (string), updated (string), split (SplitFunc)
+  - Results: Slice of Edit values
+  - Description: Compares two texts token by token using a caller supplied split function.
+
+39. DiffBy:
+  - Parameters: old ([]T), updated ([]T), key (func(T) string)
+  - Results: Slice of Edit values
+  - Description: Compares two slices of any type element by element, identifying every element by its key, with positions and lengths counted in elements.
+
+40. InvertEdits:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of Edit values
+  - Description: Returns the edits turning the updated text back into the old one, swapping added and deleted content and moving the positions to the updated text.
+
+41. DetectMoves:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of Edit values
+  - Description: Collapses deleted content added back elsewhere into a single Moved edit.
+
+42. ParsePatch:
+  - Parameters: s (string)
+  - Results: Patch, error
+  - Description: Reads the textual delta format back into a Patch, whose Apply method checks every edit against the text and String method renders it again.
+
+43. Verify:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Whether the delta reconstructs updated (bool), error
+  - Description: Compares two texts and checks that applying the resulting delta to old gives updated back.
+
+44. CollapseReplacements:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of Edit values
+  - Description: Merges every run of adjacent edits, such as a deletion followed by an addition, into a single edit replacing the whole region.
+
+45. AddSubEdits:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of Edit values
+  - Description: Attaches to every Modified edit the character level differences between its old and new content as SubEdits, positioned from the start of that content.
+
+46. Normalize:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of Edit values
+  - Description: Sorts edits by position and merges every run of contiguous edits of the same kind into one.
+
+47. ApplyPatch:
+  - Parameters: old (string), delta (string)
+  - Results: Updated text, error
+  - Description: Applies a delta produced by Diff to old, returning an error when a line of the delta is malformed.
+
+48. ReplaceDelta:
+  - Parameters: old (string), delta (string)
+  - Results: Updated text
+  - Description: Applies a delta produced by Diff to the old text, returning the old text unchanged when the delta is malformed.
+
+49. ReverseDelta:
+  - Parameters: updated (string), delta (string)
+  - Results: Old text
+  - Description: Undoes a delta produced by Diff using InvertEdits.
+
+50. DiffCounts:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: ChangeCounts, error
+  - Description: Counts the edits between two texts by kind and the characters they touch without building their content.
+
+51. Similarity:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Score between 0.0 and 1.0
+  - Description: Measures how alike two texts are from the characters touched by their edits.
+
+52. Merge:
+  - Parameters: base (string), a (string), b (string), windowSize (int)
+  - Results: Merged text, slice of Conflict values, error
+  - Description: Combines the edits from base to a and from base to b, keeping the base content of regions both sides changed differently and reporting them as conflicts.
+
+53. ClosestMatch:
+  - Parameters: target (string), candidates ([]string), windowSize (int)
+  - Results: Index (int), score (float64)
+  - Description: Returns the candidate most similar to the target and its Similarity score, stopping at an exact match; -1 when there are no candidates.
+
+54. EditDistance:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Distance (int)
+  - Description: Returns the number of characters deleted plus added between two texts, a modified character counting twice, or -1 for invalid input.
+
+55. Summarize:
+  - Parameters: edits ([]Edit)
+  - Results: Number of added, deleted and modified edits
+  - Description: Counts edits by kind, a moved block counting as one deletion and one addition.
+
+56. ChangedChars:
+  - Parameters: edits ([]Edit)
+  - Results: Number of changed characters (int)
+  - Description: Totals the characters touched by the edits, counting the longest side of each modification.
+
+57. Locations:
+  - Parameters: edits ([]Edit)
+  - Results: Slice of positions ([]int)
+  - Description: Returns the position in the old text where every edit starts.
+
+58. FormatLocations:
+  - Parameters: edits ([]Edit)
+  - Results: One op:start:length line per edit (string)
+  - Description: Renders only the kind, 0-based start and old length of every edit, for editor integration.
+
+59. FormatUnified:
+  - Parameters: old (string), updated (string), edits ([]Edit)
+  - Results: Unified diff string
+  - Description: Renders edits as a GNU unified diff that can be consumed by patch.
+
+60. FormatUnifiedContext:
+  - Parameters: old (string), updated (string), edits ([]Edit), context (int)
+  - Results: Unified diff (string)
+  - Description: Renders edits as a GNU unified diff showing context unchanged lines around every change, grouping changes closer than twice the context in one hunk.
+
+61. FormatColor:
+  - Parameters: edits ([]Edit)
+  - Results: Colored delta (string)
+  - Description: Renders edits in the textual delta format with deletions in red and additions in green using ANSI escape codes.
+
+62. FormatHTML:
+  - Parameters: old (string), updated (string), edits ([]Edit)
+  - Results: HTML table (string)
+  - Description: Renders old and updated side by side in an HTML table, highlighting deleted and added content and escaping all text.
+
+63. DiffJSON:
+  - Parameters: old (string), updated (string)
+  - Results: Slice of Edit values, error
+  - Description: Compares two JSON objects key by key and reports the changed values with their dotted paths.
+
+64. PositionOf:
+  - Parameters: text (string), pos (int)
+  - Results: Line and column (Position)
+  - Description: Returns the 1-based line and column of the rune at a position of a text.
+
+65. LineSpans:
+  - Parameters: old (string), edits ([]Edit)
+  - Results: Slice of LineSpan values
+  - Description: Returns the line and column where every edit starts and ends in the old text.
+
+66. FormatLineColumns:
+  - Parameters: old (string), edits ([]Edit), color (bool)
+  - Results: Delta with line and column positions (string)
+  - Description: Renders edits in the textual delta format with line and column positions of the old text.
+
+67. DiffMulti:
+  - Parameters: versions ([]string), windowSize (int)
+  - Results: Slice of edit lists, one per step, error
+  - Description: Compares every version of a text with the next one.
+
+68. SummarizeChurn:
+  - Parameters: steps ([][]Edit)
+  - Results: Slice of Churn values
+  - Description: Counts the edits and changed characters of every step and their running totals.
+
+69. DiffStream:
+  - Parameters: old (string), updated (string), windowSize (int), emit (func(Edit) error)
+  - Results: error
+  - Description: Compares two texts handing every edit to a callback as soon as it is found, stopping when the callback fails.
+
+70. DiffSegments:
+  - Parameters: old (string), updated (string), windowSize (int)
+  - Results: Slice of Segment values, error
+  - Description: Returns the unchanged runs interleaved with the edits, together covering both texts in order.
+
+71. Compare:
+  - Parameters: a (string), b (string)
+  - Results: Relation
+  - Description: Classifies the updated text as identical, added to, deleted from, modified or a mix of those.
+
+72. NewComparer:
+  - Parameters: opts (DiffOptions)
+  - Results: Comparer (*Comparer), error
+  - Description: Returns a Comparer applying the options to every comparison, after validating the hash parameters.
+
+73. Comparer.Diff:
+  - Parameters: old (string), updated (string)
+  - Results: Edits ([]Edit), error
+  - Description: Compares two texts with the settings of the Comparer, reusing the search buffers of the previous comparison. A Comparer is not safe for concurrent use.
+
+74. ApplyEdits:
+  - Parameters: old (string), edits ([]Edit)
+  - Results: Updated text (string), error
+  - Description: Applies structured edits directly to the old text, without the textual delta format, failing on edits that do not fit it.
+
+75. DiffLineStream:
+  - Parameters: old (*bufio.Scanner), updated (*bufio.Scanner), lookahead (int), emit (func(Edit) error)
+  - Results: error
+  - Description: Compares two streams of lines as they are read, buffering at most lookahead lines of each to resynchronize after a difference, and hands every added, deleted or changed run of lines to emit.
+
+76. ApplyEditsWithMap:
+  - Parameters: old (string), edits ([]Edit)
+  - Results: Updated text (string), applied edits ([]AppliedEdit), error
+  - Description: Applies edits like ApplyEdits and reports the position and length of every change in the produced text.
+
+77. MarshalEdits:
+  - Parameters: edits ([]Edit)
+  - Results: JSON (byte slice), error
+  - Description: Serializes edits as a JSON array of objects with the JSON fields of Edit, encoding an empty comparison as an empty array.
+
+78. EncodeDeltaBinary:
+  - Parameters: edits ([]Edit)
+  - Results: Byte slice
+  - Description: Serializes edits in a compact varint based binary form, an alternative to the textual delta for storage and transmission.
+
+79. DecodeDeltaBinary:
+  - Parameters: data ([]byte)
+  - Results: Slice of Edit values, error
+  - Description: Reads edits back from the binary form produced by EncodeDeltaBinary, failing on truncated or corrupt data.
+*/
+package textcompare
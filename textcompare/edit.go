@@ -0,0 +1,263 @@
+package textcompare
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// OpKind identifies the kind of change described by an Edit.
+type OpKind int
+
+const (
+	// Added marks content present only in the updated text.
+	Added OpKind = iota
+	// Deleted marks content present only in the old text.
+	Deleted
+	// Modified marks content of the old text replaced by new content.
+	Modified
+	// Moved marks content of the old text relocated to another position.
+	// Moved edits are only produced by DetectMoves.
+	Moved
+)
+
+// String returns the lower case name of the operation.
+func (k OpKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Deleted:
+		return "deleted"
+	case Modified:
+		return "modified"
+	case Moved:
+		return "moved"
+	}
+	return "unknown"
+}
+
+// Edit describes a single change between two texts.
+// Start is the rune index in the old text where the change begins, Old is the
+// content removed from the old text and New is the content that replaces it.
+// NewStart is the rune index in the updated text where New begins, which for
+// a deletion is where the removed content used to be. OldLen and NewLen are
+// the lengths of Old and New in runes. Comparisons of other units count
+// positions and lengths in that same unit, such as lines for DiffLines or
+// bytes for DiffBytes, and never mix units within an edit.
+// WhitespaceOnly is set when Old and New differ only in whitespace, such as
+// a reindented line, so that a UI can de-emphasize the edit.
+// For Moved edits Old and New hold the relocated content and To is the rune
+// index in the old text where it is inserted again.
+// Path locates the changed value inside a structured document, such as
+// "a.b[2]", and is only set by DiffJSON.
+// SubEdits, set on Modified edits by AddSubEdits, are the character level
+// differences between Old and New, with Start and NewStart counted from the
+// beginning of Old and New.
+type Edit struct {
+	Op       OpKind `json:"op"`
+	Start    int    `json:"start"`
+	NewStart int    `json:"newStart"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+	OldLen   int    `json:"oldLen"`
+	NewLen   int    `json:"newLen"`
+	To       int    `json:"to,omitempty"`
+	Path     string `json:"path,omitempty"`
+
+	WhitespaceOnly bool   `json:"whitespaceOnly,omitempty"`
+	SubEdits       []Edit `json:"subEdits,omitempty"`
+}
+
+// newEdit builds the edit replacing previous with next at start, classifying
+// it as added, deleted or modified depending on which side has content.
+func newEdit(start int, previous, next string) Edit {
+	return newEditLen(start, previous, next, utf8.RuneCountInString(previous), utf8.RuneCountInString(next))
+}
+
+// newEditLen builds the edit replacing previous with next at start like
+// newEdit, given their lengths in the unit of the edits it comes from.
+func newEditLen(start int, previous, next string, oldLen, newLen int) Edit {
+	return Edit{Op: opFor(oldLen, newLen), Start: start, Old: previous, New: next,
+		OldLen: oldLen, NewLen: newLen, WhitespaceOnly: whitespaceOnly(previous, next)}
+}
+
+// opFor classifies an edit replacing oldLen runes with newLen runes.
+func opFor(oldLen, newLen int) OpKind {
+	if oldLen == 0 {
+		return Added
+	} else if newLen == 0 {
+		return Deleted
+	}
+	return Modified
+}
+
+// whitespaceOnly reports whether previous and next are equal once all their
+// whitespace is removed.
+func whitespaceOnly(previous, next string) bool {
+	return strings.Join(strings.Fields(previous), "") == strings.Join(strings.Fields(next), "")
+}
+
+// setNewStarts fills the NewStart of edits sorted by Start, whose old and
+// updated positions are counted from the same origin.
+func setNewStarts(edits []Edit) []Edit {
+	offset := 0
+	for i := range edits {
+		edits[i].NewStart = edits[i].Start + offset
+		offset += edits[i].NewLen - edits[i].OldLen
+	}
+	return edits
+}
+
+// InvertEdits returns the edits that turn the updated text back into the old
+// one. Added content becomes deleted and vice versa, modifications swap their
+// content, and positions are moved to the updated text.
+func InvertEdits(edits []Edit) []Edit {
+	edits = expandMoves(edits)
+	inverted := make([]Edit, 0, len(edits))
+	offset := 0
+	for _, edit := range edits {
+		inverse := newEditLen(edit.Start+offset, edit.New, edit.Old, edit.NewLen, edit.OldLen)
+		inverse.NewStart = edit.Start
+		inverted = append(inverted, inverse)
+		offset += edit.NewLen - edit.OldLen
+	}
+	return inverted
+}
+
+// DetectMoves collapses every deletion whose content is added back elsewhere
+// into a single Moved edit recording both positions. Other edits are returned
+// unchanged, so the pass can be applied to the result of any comparison.
+func DetectMoves(edits []Edit) []Edit {
+	// Pair every deletion with an addition of the same content first, so an
+	// addition placed before its deletion is not kept as well
+	partner := make([]int, len(edits))
+	matched := make([]bool, len(edits))
+	for i, edit := range edits {
+		partner[i] = -1
+		if edit.Op != Deleted {
+			continue
+		}
+		for j, other := range edits {
+			if !matched[j] && other.Op == Added && other.New == edit.Old {
+				matched[j] = true
+				partner[i] = j
+				break
+			}
+		}
+	}
+	result := make([]Edit, 0, len(edits))
+	for i, edit := range edits {
+		if matched[i] {
+			continue
+		}
+		if j := partner[i]; j >= 0 {
+			other := edits[j]
+			edit = Edit{Op: Moved, Start: edit.Start, NewStart: other.NewStart, Old: edit.Old, New: edit.Old,
+				OldLen: edit.OldLen, NewLen: edit.OldLen, To: other.Start}
+		}
+		result = append(result, edit)
+	}
+	return result
+}
+
+// AddSubEdits attaches to every Modified edit the character level
+// differences between its Old and New content, found with DiffMinimal, so the
+// characters that really changed can be told from those that only happen to
+// be replaced along with them. Other edits are returned unchanged.
+func AddSubEdits(edits []Edit) []Edit {
+	for i, edit := range edits {
+		if edit.Op == Modified {
+			edits[i].SubEdits = DiffMinimal(edit.Old, edit.New)
+		}
+	}
+	return edits
+}
+
+// CollapseReplacements merges every run of adjacent edits, such as a deletion
+// immediately followed by an addition, into a single edit replacing the whole
+// region, which is Modified whenever it has content on both sides. Edits that
+// are not adjacent and Moved edits are returned unchanged.
+func CollapseReplacements(edits []Edit) []Edit {
+	result := make([]Edit, 0, len(edits))
+	for _, edit := range edits {
+		if n := len(result); n > 0 && edit.Op != Moved && result[n-1].Op != Moved &&
+			result[n-1].Start+result[n-1].OldLen == edit.Start {
+			last := result[n-1]
+			merged := newEditLen(last.Start, last.Old+edit.Old, last.New+edit.New, last.OldLen+edit.OldLen, last.NewLen+edit.NewLen)
+			merged.NewStart = last.NewStart
+			result[n-1] = merged
+			continue
+		}
+		result = append(result, edit)
+	}
+	return result
+}
+
+// absorbShortMatches merges edits separated by fewer than minMatch unchanged
+// runes of old into a single edit covering the unchanged runs between them,
+// so that accidental short matches do not fragment a change. Edits must be
+// sorted by Start, with NewStart set, and hold no Moved edit.
+func absorbShortMatches(old string, edits []Edit, minMatch int) []Edit {
+	oldRunes := []rune(old)
+	result := make([]Edit, 0, len(edits))
+	for _, edit := range edits {
+		if n := len(result); n > 0 {
+			last := result[n-1]
+			if end := last.Start + last.OldLen; edit.Start-end < minMatch {
+				between := string(oldRunes[end:edit.Start])
+				merged := newEdit(last.Start, last.Old+between+edit.Old, last.New+between+edit.New)
+				merged.NewStart = last.NewStart
+				result[n-1] = merged
+				continue
+			}
+		}
+		result = append(result, edit)
+	}
+	return result
+}
+
+// Normalize sorts edits by position and merges every run of contiguous edits
+// of the same kind into one, so single character modifications at 5, 6 and 7
+// become a single modification of 5 to 7. Moved edits are never merged.
+func Normalize(edits []Edit) []Edit {
+	sorted := slices.Clone(edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	result := make([]Edit, 0, len(sorted))
+	for _, edit := range sorted {
+		if n := len(result); n > 0 && edit.Op != Moved && result[n-1].Op == edit.Op &&
+			result[n-1].Start+result[n-1].OldLen == edit.Start {
+			last := result[n-1]
+			merged := newEditLen(last.Start, last.Old+edit.Old, last.New+edit.New, last.OldLen+edit.OldLen, last.NewLen+edit.NewLen)
+			merged.NewStart = last.NewStart
+			result[n-1] = merged
+			continue
+		}
+		result = append(result, edit)
+	}
+	return result
+}
+
+// expandMoves replaces every Moved edit by the deletion and addition it
+// stands for, keeping the edits sorted by position and their NewStart in step.
+func expandMoves(edits []Edit) []Edit {
+	hasMoves := false
+	for _, edit := range edits {
+		hasMoves = hasMoves || edit.Op == Moved
+	}
+	if !hasMoves {
+		return edits
+	}
+	expanded := make([]Edit, 0, len(edits)+1)
+	for _, edit := range edits {
+		if edit.Op == Moved {
+			expanded = append(expanded,
+				newEditLen(edit.Start, edit.Old, "", edit.OldLen, 0),
+				newEditLen(edit.To, "", edit.New, 0, edit.NewLen))
+			continue
+		}
+		expanded = append(expanded, edit)
+	}
+	sort.SliceStable(expanded, func(i, j int) bool { return expanded[i].Start < expanded[j].Start })
+	return setNewStarts(expanded)
+}
@@ -0,0 +1,363 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffEdits(t *testing.T) {
+	// Test each kind of edit is reported as structured data
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{"Added content at the end", "hello", "hello world", []Edit{{Op: Added, Start: 5, NewStart: 5, New: " world", NewLen: 6}}},
+		{"Deleted content at the end", "hello world", "hello", []Edit{{Op: Deleted, Start: 5, NewStart: 5, Old: " world", OldLen: 6}}},
+		{"Modified content in the middle", "hello world", "hello xorld", []Edit{{Op: Modified, Start: 6, NewStart: 6, Old: "w", New: "x", OldLen: 1, NewLen: 1}}},
+		{"No changes", "hello world", "hello world", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := DiffEdits(tt.oldText, tt.updated, 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+}
+
+func TestFormatEdits(t *testing.T) {
+	// Test the textual delta is produced from the structured edits
+	t.Run("Format matches checkString", func(t *testing.T) {
+		edits, _ := DiffEdits("hello world", "hello there", 2)
+		delta := mustCheckString(t, "hello world", "hello there", 2, 0)
+		if formatEdits(edits) != delta {
+			t.Errorf("Test failed. Expected: %q Got: %q", delta, formatEdits(edits))
+		}
+	})
+
+	// Test content containing the delta prefix survives the round trip
+	t.Run("Content containing the start marker", func(t *testing.T) {
+		oldText := "note"
+		updatedText := "note Start character: 3 [--- x]"
+		delta := mustCheckString(t, oldText, updatedText, 2, 0)
+		expectedAddedContent := ReplaceDelta(oldText, delta)
+		if updatedText != expectedAddedContent {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, expectedAddedContent)
+		}
+	})
+}
+
+func TestDetectMoves(t *testing.T) {
+	// Test that a block moved from the front to the back becomes a single edit
+	t.Run("Block moved to the back", func(t *testing.T) {
+		oldText := "alpha beta gamma delta "
+		updatedText := "gamma delta alpha beta "
+		edits := []Edit{
+			{Op: Deleted, Start: 0, Old: "alpha beta ", OldLen: 11},
+			{Op: Added, Start: 23, New: "alpha beta ", NewLen: 11},
+		}
+		expected := []Edit{{Op: Moved, Start: 0, Old: "alpha beta ", New: "alpha beta ", OldLen: 11, NewLen: 11, To: 23}}
+		moved := DetectMoves(edits)
+		if !reflect.DeepEqual(moved, expected) {
+			t.Fatalf("Test failed. Expected: %+v Got: %+v", expected, moved)
+		}
+		if got := applyEdits(oldText, moved); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+		if got := applyEdits(updatedText, InvertEdits(moved)); got != oldText {
+			t.Errorf("Test failed. Expected: %s Got: %s", oldText, got)
+		}
+	})
+
+	// Test that a block moved from the back to the front, whose addition comes first, is inserted once
+	t.Run("Block moved to the front", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Added, Start: 0, New: "."},
+			{Op: Deleted, Start: 3, Old: "."},
+		}
+		expected := []Edit{{Op: Moved, Start: 3, Old: ".", New: ".", To: 0}}
+		if got := DetectMoves(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that unrelated deletions and additions are left alone
+	t.Run("Different content", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 0, Old: "alpha ", OldLen: 6},
+			{Op: Modified, Start: 6, Old: "b", New: "B", OldLen: 1, NewLen: 1},
+			{Op: Added, Start: 23, New: " omega", NewLen: 6},
+		}
+		if got := DetectMoves(edits); !reflect.DeepEqual(got, edits) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", edits, got)
+		}
+	})
+
+	// Test that the default comparison never reports moves
+	t.Run("Default comparison", func(t *testing.T) {
+		edits, _ := DiffEdits("alpha beta gamma delta ", "gamma delta alpha beta ", 2)
+		for _, edit := range edits {
+			if edit.Op == Moved {
+				t.Errorf("Test failed. Unexpected moved edit: %+v", edit)
+			}
+		}
+	})
+}
+
+func TestNewStart(t *testing.T) {
+	// Test that every kind of edit records its position in both texts
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{"Addition", "hello", "hello world", []Edit{{Op: Added, Start: 5, NewStart: 5, New: " world", NewLen: 6}}},
+		{"Deletion", "hello cruel world", "hello world", []Edit{{Op: Deleted, Start: 6, NewStart: 6, Old: "cruel ", OldLen: 6}}},
+		{"Modification", "año nuevo", "año_nuevo", []Edit{{Op: Modified, Start: 3, NewStart: 3, Old: " ", New: "_", OldLen: 1, NewLen: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := DiffEdits(tt.oldText, tt.updated, 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+
+	// Test that later edits are shifted by the earlier ones
+	t.Run("Shifted by earlier edits", func(t *testing.T) {
+		patch, err := ParsePatch("Start character: 1 [+++ xx]\nStart character: 6 [---  ef]\nStart character: 10 [--- g][+++ h]\n")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := Patch{
+			{Op: Added, Start: 0, NewStart: 0, New: "xx", NewLen: 2},
+			{Op: Deleted, Start: 5, NewStart: 7, Old: " ef", OldLen: 3},
+			{Op: Modified, Start: 9, NewStart: 8, Old: "g", New: "h", OldLen: 1, NewLen: 1},
+		}
+		if !reflect.DeepEqual(patch, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, patch)
+		}
+	})
+
+	// Test that NewStart points at the new content in the updated text
+	t.Run("Positions match the texts", func(t *testing.T) {
+		oldText, updatedText := "the quick brown fox", "a quick red fox jumps"
+		edits, err := DiffEdits(oldText, updatedText, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		oldRunes, newRunes := []rune(oldText), []rune(updatedText)
+		for _, edit := range edits {
+			if got := string(oldRunes[edit.Start : edit.Start+len([]rune(edit.Old))]); got != edit.Old {
+				t.Errorf("Test failed. Expected: %q Got: %q", edit.Old, got)
+			}
+			if got := string(newRunes[edit.NewStart : edit.NewStart+len([]rune(edit.New))]); got != edit.New {
+				t.Errorf("Test failed. Expected: %q Got: %q", edit.New, got)
+			}
+		}
+	})
+
+	// Test that a moved block records where it lands in the updated text
+	t.Run("Moved block", func(t *testing.T) {
+		moves := DetectMoves(DiffWords("alpha beta gamma delta ", "gamma delta alpha beta "))
+		for _, edit := range moves {
+			if edit.Op == Moved && edit.NewStart != 12 {
+				t.Errorf("Test failed. Expected: 12 Got: %d", edit.NewStart)
+			}
+		}
+	})
+}
+
+func TestAddSubEdits(t *testing.T) {
+	// Test that a replaced word sharing a prefix and suffix is narrowed to the changed middle
+	t.Run("Shared prefix and suffix", func(t *testing.T) {
+		edits := AddSubEdits(DiffWords("she was unhappily late", "she was unluckily late"))
+		expected := []Edit{{Op: Modified, Start: 8, NewStart: 8, Old: "unhappily", New: "unluckily", OldLen: 9, NewLen: 9,
+			SubEdits: []Edit{{Op: Modified, Start: 2, NewStart: 2, Old: "happ", New: "luck", OldLen: 4, NewLen: 4}}}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that only modifications get sub-edits
+	t.Run("Other edits", func(t *testing.T) {
+		edits := []Edit{{Op: Added, Start: 3, NewStart: 3, New: "abc", NewLen: 3}, {Op: Deleted, Start: 5, NewStart: 8, Old: "xy", OldLen: 2}}
+		expected := append([]Edit(nil), edits...)
+		if got := AddSubEdits(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that the option splits a modification spanning an unchanged run
+	t.Run("Option", func(t *testing.T) {
+		edits, err := DiffWithOptions("the cat sat down", "the dog sit down", DiffOptions{WindowSize: 2, MinMatch: 4, SubEdits: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 4, NewStart: 4, Old: "cat sa", New: "dog si", OldLen: 6, NewLen: 6, SubEdits: []Edit{
+			{Op: Modified, Start: 0, NewStart: 0, Old: "cat", New: "dog", OldLen: 3, NewLen: 3},
+			{Op: Modified, Start: 5, NewStart: 5, Old: "a", New: "i", OldLen: 1, NewLen: 1},
+		}}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
+
+func TestCollapseReplacements(t *testing.T) {
+	// Test that a deletion immediately followed by an addition becomes one replacement
+	t.Run("Delete then add", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 4, NewStart: 4, Old: "cat", OldLen: 3},
+			{Op: Added, Start: 7, NewStart: 4, New: "dog", NewLen: 3},
+		}
+		expected := []Edit{{Op: Modified, Start: 4, NewStart: 4, Old: "cat", New: "dog", OldLen: 3, NewLen: 3}}
+		if got := CollapseReplacements(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that runs of modifications and deletions collapse together
+	t.Run("Modification then deletion", func(t *testing.T) {
+		oldText, updatedText := "one two three", "one 2 three"
+		edits, err := DiffEdits(oldText, updatedText, 1)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 4, NewStart: 4, Old: "two", New: "2", OldLen: 3, NewLen: 1}}
+		got := CollapseReplacements(edits)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+		if applyEdits(oldText, got) != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, applyEdits(oldText, got))
+		}
+	})
+
+	// Test that separate edits and moves are kept
+	t.Run("Separate edits", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 0, Old: "ab", OldLen: 2},
+			{Op: Added, Start: 3, NewStart: 1, New: "x", NewLen: 1},
+			{Op: Moved, Start: 5, Old: "yz", New: "yz", OldLen: 2, NewLen: 2, To: 9},
+			{Op: Added, Start: 7, New: "w", NewLen: 1},
+		}
+		if got := CollapseReplacements(edits); !reflect.DeepEqual(got, edits) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", edits, got)
+		}
+	})
+
+	// Test that a single replacement is left alone
+	t.Run("Single replacement", func(t *testing.T) {
+		edits, _ := DiffEdits("one two three", "one 2 three", 1)
+		if got := CollapseReplacements(edits); !reflect.DeepEqual(got, edits) || len(got) != 1 {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", edits, got)
+		}
+	})
+}
+
+func TestNormalize(t *testing.T) {
+	// Test that adjacent single character modifications collapse into one
+	t.Run("Adjacent modifications", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Modified, Start: 5, NewStart: 5, Old: "a", New: "x", OldLen: 1, NewLen: 1},
+			{Op: Modified, Start: 6, NewStart: 6, Old: "b", New: "y", OldLen: 1, NewLen: 1},
+			{Op: Modified, Start: 7, NewStart: 7, Old: "c", New: "z", OldLen: 1, NewLen: 1},
+		}
+		expected := []Edit{{Op: Modified, Start: 5, NewStart: 5, Old: "abc", New: "xyz", OldLen: 3, NewLen: 3}}
+		if got := Normalize(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that unsorted edits are sorted before merging
+	t.Run("Unsorted edits", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 3, NewStart: 2, Old: "d", OldLen: 1},
+			{Op: Deleted, Start: 0, NewStart: 0, Old: "a", OldLen: 1},
+			{Op: Deleted, Start: 2, NewStart: 2, Old: "c", OldLen: 1},
+		}
+		expected := []Edit{
+			{Op: Deleted, Start: 0, NewStart: 0, Old: "a", OldLen: 1},
+			{Op: Deleted, Start: 2, NewStart: 2, Old: "cd", OldLen: 2},
+		}
+		got := Normalize(edits)
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+		if applyEdits("abcde", got) != "be" {
+			t.Errorf("Test failed. Expected: be Got: %s", applyEdits("abcde", got))
+		}
+		if edits[0].Start != 3 {
+			t.Errorf("Test failed. Expected the input to be left unchanged Got: %+v", edits)
+		}
+	})
+
+	// Test that edits of different kinds or with a gap are kept apart
+	t.Run("Different kinds", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 0, NewStart: 0, Old: "ab", OldLen: 2},
+			{Op: Modified, Start: 2, NewStart: 0, Old: "c", New: "x", OldLen: 1, NewLen: 1},
+			{Op: Modified, Start: 4, NewStart: 2, Old: "e", New: "y", OldLen: 1, NewLen: 1},
+		}
+		if got := Normalize(edits); !reflect.DeepEqual(got, edits) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", edits, got)
+		}
+	})
+}
+
+func TestWhitespaceOnly(t *testing.T) {
+	// Test that reindenting a line is a whitespace only edit
+	t.Run("Reindented line", func(t *testing.T) {
+		edits, err := DiffEdits("if x {\n\treturn\n}", "if x {\n    return\n}", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 7, NewStart: 7, Old: "\t", New: "    ", OldLen: 1, NewLen: 4, WhitespaceOnly: true}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that substantive changes are not whitespace only
+	t.Run("Substantive change", func(t *testing.T) {
+		edits, err := DiffEdits("if x {\n\treturn\n}", "if x {\n\treturn nil\n}", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		for _, edit := range edits {
+			if edit.WhitespaceOnly {
+				t.Errorf("Test failed. Expected: a substantive edit Got: %+v", edit)
+			}
+		}
+	})
+
+	// Test the classification of edit content
+	tests := []struct {
+		previous, next string
+		expected       bool
+	}{
+		{"  ", "\t", true},
+		{"", "\n\n", true},
+		{"a b", "a  b", true},
+		{"a b", "ab", true},
+		{"a", "b", false},
+		{" ", " x", false},
+	}
+	for _, tt := range tests {
+		if got := newEdit(0, tt.previous, tt.next).WhitespaceOnly; got != tt.expected {
+			t.Errorf("Test failed. %q %q Expected: %t Got: %t", tt.previous, tt.next, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package textcompare
+
+import "unicode"
+
+const (
+	zeroWidthJoiner = '\u200D'
+	regionalFirst   = '\U0001F1E6'
+	regionalLast    = '\U0001F1FF'
+)
+
+// extendsCluster reports whether r belongs to the cluster of the rune before
+// it: combining marks, variation selectors, emoji skin tone modifiers and
+// emoji tag characters.
+func extendsCluster(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		r == zeroWidthJoiner ||
+		(r >= '\uFE00' && r <= '\uFE0F') ||
+		(r >= '\U0001F3FB' && r <= '\U0001F3FF') ||
+		(r >= '\U000E0020' && r <= '\U000E007F')
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= regionalFirst && r <= regionalLast
+}
+
+// splitGraphemes splits text into grapheme clusters, so that a base character
+// and its combining accents, an emoji joined with zero width joiners and a
+// flag made of two regional indicators are each kept together. It covers the
+// common cases of Unicode text segmentation rather than the full rules.
+func splitGraphemes(text string) []string {
+	var clusters []string
+	start := 0
+	var previous rune
+	regionalRun := 0
+	for i, r := range text {
+		joined := i > start && (extendsCluster(r) || previous == zeroWidthJoiner ||
+			(previous == '\r' && r == '\n') ||
+			(isRegionalIndicator(r) && regionalRun%2 == 1))
+		if i > start && !joined {
+			clusters = append(clusters, text[start:i])
+			start = i
+		}
+		if isRegionalIndicator(r) {
+			regionalRun++
+		} else {
+			regionalRun = 0
+		}
+		previous = r
+	}
+	if start < len(text) {
+		clusters = append(clusters, text[start:])
+	}
+	return clusters
+}
+
+// GraphemeCount returns the number of grapheme clusters in text, the unit of
+// the positions reported by DiffGraphemes.
+func GraphemeCount(text string) int {
+	return len(splitGraphemes(text))
+}
+
+// DiffGraphemes compares two texts grapheme cluster by grapheme cluster. A
+// character with combining accents or an emoji sequence is an atomic unit,
+// so it is never split by an edit. Edit positions, NewStart included, and
+// lengths are counted in clusters rather than runes.
+func DiffGraphemes(old, updated string) []Edit {
+	return diffTokens(splitGraphemes(old), splitGraphemes(updated), "")
+}
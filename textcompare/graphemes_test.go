@@ -0,0 +1,71 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGraphemes(t *testing.T) {
+	// Test that clusters made of several code points are kept together
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Combining accent", "cafe\u0301!", []string{"c", "a", "f", "e\u0301", "!"}},
+		{"Family emoji", "a\U0001F468\u200D\U0001F469\u200D\U0001F467b", []string{"a", "\U0001F468\u200D\U0001F469\u200D\U0001F467", "b"}},
+		{"Skin tone", "\U0001F44B\U0001F3FD\U0001F44B", []string{"\U0001F44B\U0001F3FD", "\U0001F44B"}},
+		{"Flags", "\U0001F1EA\U0001F1F8\U0001F1EB\U0001F1F7", []string{"\U0001F1EA\U0001F1F8", "\U0001F1EB\U0001F1F7"}},
+		{"Line ending", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"Empty text", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitGraphemes(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Test failed. Expected: %q Got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffGraphemes(t *testing.T) {
+	// Test that a changed combining accent replaces the whole character
+	t.Run("Combining accent", func(t *testing.T) {
+		edits := DiffGraphemes("cafe\u0301 noir", "cafe\u0300 noir")
+		expected := []Edit{{Op: Modified, Start: 3, NewStart: 3, Old: "e\u0301", New: "e\u0300", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that a multi code point emoji is a single unit
+	t.Run("Family emoji", func(t *testing.T) {
+		family := "\U0001F468\u200D\U0001F469\u200D\U0001F467"
+		edits := DiffGraphemes("we are "+family+" here", "we are "+family+"\U0001F44B here")
+		expected := []Edit{{Op: Added, Start: 8, NewStart: 8, New: "\U0001F44B", NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that replacing one emoji sequence by another is one modification
+	t.Run("Emoji replaced", func(t *testing.T) {
+		family := "\U0001F468\u200D\U0001F469\u200D\U0001F467"
+		couple := "\U0001F468\u200D\U0001F469"
+		edits := DiffGraphemes("x"+family+"y", "x"+couple+"y")
+		expected := []Edit{{Op: Modified, Start: 1, NewStart: 1, Old: family, New: couple, OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that NewStart is counted in clusters after earlier edits
+	t.Run("Positions after earlier edits", func(t *testing.T) {
+		edits := DiffGraphemes("e\u0301 a b", "a b c")
+		for _, edit := range edits {
+			if edit.Op == Added && edit.NewStart != 3 {
+				t.Errorf("Test failed. Expected: 3 Got: %d", edit.NewStart)
+			}
+		}
+	})
+}
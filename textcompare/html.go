@@ -0,0 +1,45 @@
+package textcompare
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	htmlDeletedStyle = "background-color:#ffd7d5"
+	htmlAddedStyle   = "background-color:#ccffd8"
+)
+
+// FormatHTML renders a side-by-side HTML table with old on the left and
+// updated on the right. Deleted content is highlighted on the left column and
+// added content on the right one, modifications appearing on both. All text is
+// escaped, so the result can be embedded in a page as is.
+func FormatHTML(old, updated string, edits []Edit) string {
+	oldRunes := []rune(old)
+	var left, right strings.Builder
+	pos := 0
+	for _, edit := range expandMoves(edits) {
+		start := min(max(edit.Start, pos), len(oldRunes))
+		unchanged := html.EscapeString(string(oldRunes[pos:start]))
+		left.WriteString(unchanged)
+		right.WriteString(unchanged)
+		if edit.Old != "" {
+			left.WriteString(`<del style="` + htmlDeletedStyle + `">` + html.EscapeString(edit.Old) + "</del>")
+		}
+		if edit.New != "" {
+			right.WriteString(`<ins style="` + htmlAddedStyle + `">` + html.EscapeString(edit.New) + "</ins>")
+		}
+		pos = min(start+len([]rune(edit.Old)), len(oldRunes))
+	}
+	unchanged := html.EscapeString(string(oldRunes[pos:]))
+	left.WriteString(unchanged)
+	right.WriteString(unchanged)
+
+	var sb strings.Builder
+	sb.WriteString("<table class=\"diff\">\n")
+	sb.WriteString("<tr><th>old</th><th>updated</th></tr>\n")
+	sb.WriteString("<tr><td style=\"white-space:pre-wrap\">" + left.String() + "</td>")
+	sb.WriteString("<td style=\"white-space:pre-wrap\">" + right.String() + "</td></tr>\n")
+	sb.WriteString("</table>\n")
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package textcompare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatHTML(t *testing.T) {
+	// Test that markup in the input is escaped
+	t.Run("Escaped markup", func(t *testing.T) {
+		oldText := "hello"
+		updatedText := "hello <script>alert(1)</script>"
+		edits, err := DiffEdits(oldText, updatedText, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		got := FormatHTML(oldText, updatedText, edits)
+		if strings.Contains(got, "<script>") {
+			t.Errorf("Test failed. Expected: escaped markup Got: %s", got)
+		}
+		if !strings.Contains(got, "&lt;script&gt;") {
+			t.Errorf("Test failed. Expected: %s Got: %s", "&lt;script&gt;", got)
+		}
+	})
+
+	// Test that deletions and additions are highlighted on their own column
+	t.Run("Highlighted edits", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Deleted, Start: 0, Old: "ab", OldLen: 2},
+			{Op: Added, Start: 4, New: "xy", NewLen: 2},
+		}
+		got := FormatHTML("abcdef", "cdxyef", edits)
+		left, right, _ := strings.Cut(got, "</td>")
+		if !strings.Contains(left, `">ab</del>cdef`) || strings.Contains(left, "<ins") {
+			t.Errorf("Test failed. Expected: deletion on the left column Got: %s", left)
+		}
+		if !strings.Contains(right, `cd<ins style="`+htmlAddedStyle+`">xy</ins>ef`) || strings.Contains(right, "<del") {
+			t.Errorf("Test failed. Expected: addition on the right column Got: %s", right)
+		}
+	})
+}
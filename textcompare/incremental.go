@@ -0,0 +1,67 @@
+package textcompare
+
+import "unicode/utf8"
+
+// IncrementalDiff keeps the comparison of an old text against an updated text
+// that grows over time. The start shared by both texts is remembered, so every
+// append only compares the content after it again.
+type IncrementalDiff struct {
+	old        string
+	updated    string
+	windowSize int
+	d          *differ
+	// prefixLen and prefixBytes measure, in runes and in bytes, the start
+	// shared by old and updated.
+	prefixLen   int
+	prefixBytes int
+	edits       []Edit
+}
+
+// NewIncrementalDiff compares old against updated and returns the state to
+// extend the comparison with Append.
+func NewIncrementalDiff(old, updated string, windowSize int) (*IncrementalDiff, error) {
+	if err := validateWindow(windowSize); err != nil {
+		return nil, err
+	}
+	inc := &IncrementalDiff{old: old, windowSize: windowSize, d: newDiffer(DiffOptions{})}
+	if _, err := inc.Append(updated); err != nil {
+		return nil, err
+	}
+	return inc, nil
+}
+
+// Append adds content to the end of the updated text and returns the edits
+// between old and the whole updated text, which are the same DiffEdits would
+// return.
+func (inc *IncrementalDiff) Append(content string) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	inc.updated += content
+	// Appending can only extend the shared start, so the search resumes there
+	oldRest, newRest := inc.old[inc.prefixBytes:], inc.updated[inc.prefixBytes:]
+	for len(oldRest) > 0 && len(newRest) > 0 {
+		r1, size := utf8.DecodeRuneInString(oldRest)
+		r2, _ := utf8.DecodeRuneInString(newRest)
+		if r1 != r2 {
+			break
+		}
+		oldRest, newRest = oldRest[size:], newRest[size:]
+		inc.prefixBytes += size
+		inc.prefixLen++
+	}
+	edits, err = inc.d.collectEdits(oldRest, newRest, inc.windowSize, inc.prefixLen)
+	if err != nil {
+		return nil, err
+	}
+	inc.edits = edits
+	return edits, nil
+}
+
+// Edits returns the edits between old and the current updated text.
+func (inc *IncrementalDiff) Edits() []Edit {
+	return inc.edits
+}
+
+// Updated returns the current updated text.
+func (inc *IncrementalDiff) Updated() string {
+	return inc.updated
+}
@@ -0,0 +1,65 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIncrementalDiff(t *testing.T) {
+	// Test that every append gives the same edits as a full comparison
+	t.Run("Matches a full diff", func(t *testing.T) {
+		old := "the quick brown fox jumps over the lazy dog"
+		chunks := []string{"the qu", "ick red ", "fox ju", "mps", " over the lazy cat", " again", "", "!"}
+		inc, err := NewIncrementalDiff(old, "", 3)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		updated := ""
+		for _, chunk := range chunks {
+			updated += chunk
+			edits, err := inc.Append(chunk)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			expected, _ := DiffEdits(old, updated, 3)
+			if !reflect.DeepEqual(edits, expected) {
+				t.Errorf("Test failed. Updated %q Expected: %+v Got: %+v", updated, expected, edits)
+			}
+		}
+		if inc.Updated() != updated || !reflect.DeepEqual(inc.Edits(), mustDiffEdits(t, old, updated, 3)) {
+			t.Errorf("Test failed. Expected: %q Got: %q", updated, inc.Updated())
+		}
+	})
+
+	// Test that the shared start is not compared again
+	t.Run("Shared start is kept", func(t *testing.T) {
+		inc, err := NewIncrementalDiff("añadir texto", "añadir", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if inc.prefixLen != 6 {
+			t.Errorf("Test failed. Expected: %d Got: %d", 6, inc.prefixLen)
+		}
+		inc.Append(" texto nuevo")
+		if inc.prefixLen != 12 || len(inc.Edits()) != 1 {
+			t.Errorf("Test failed. Expected: 12 and one edit Got: %d and %+v", inc.prefixLen, inc.Edits())
+		}
+	})
+
+	// Test that an invalid window size is rejected
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, err := NewIncrementalDiff("a", "b", 0); err == nil {
+			t.Errorf("Test failed. Expected: an error Got: nil")
+		}
+	})
+}
+
+// mustDiffEdits runs DiffEdits failing the test on error.
+func mustDiffEdits(t *testing.T, old, updated string, windowSize int) []Edit {
+	t.Helper()
+	edits, err := DiffEdits(old, updated, windowSize)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error: %v", err)
+	}
+	return edits
+}
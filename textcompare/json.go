@@ -0,0 +1,37 @@
+package textcompare
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalText encodes the operation as its lower case name.
+func (k OpKind) MarshalText() ([]byte, error) {
+	switch k {
+	case Added, Deleted, Modified, Moved:
+		return []byte(k.String()), nil
+	}
+	return nil, &CustomError{message: fmt.Sprintf("unknown operation %d", int(k))}
+}
+
+// UnmarshalText decodes an operation from its lower case name.
+func (k *OpKind) UnmarshalText(text []byte) error {
+	for _, op := range []OpKind{Added, Deleted, Modified, Moved} {
+		if string(text) == op.String() {
+			*k = op
+			return nil
+		}
+	}
+	return &CustomError{message: fmt.Sprintf("unknown operation %q", text)}
+}
+
+// MarshalEdits serializes edits as a JSON array of objects holding the JSON
+// fields of Edit: op, start, newStart, old, new, oldLen and newLen, plus to,
+// path, whitespaceOnly and subEdits when they are set. An empty comparison is
+// encoded as an empty array.
+func MarshalEdits(edits []Edit) ([]byte, error) {
+	if edits == nil {
+		edits = []Edit{}
+	}
+	return json.Marshal(edits)
+}
@@ -0,0 +1,45 @@
+package textcompare
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMarshalEdits(t *testing.T) {
+	// Test the field names and operation names of the JSON output
+	t.Run("Field names", func(t *testing.T) {
+		data, err := MarshalEdits([]Edit{{Op: Modified, Start: 6, NewStart: 6, Old: "w", New: "x", OldLen: 1, NewLen: 1}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := `[{"op":"modified","start":6,"newStart":6,"old":"w","new":"x","oldLen":1,"newLen":1}]`
+		if string(data) != expected {
+			t.Errorf("Test failed. Expected: %s Got: %s", expected, data)
+		}
+	})
+
+	// Test that quotes and newlines are escaped and survive decoding
+	t.Run("Escaped content", func(t *testing.T) {
+		edits := []Edit{{Op: Added, Start: 0, New: "say \"hi\"\nbye", NewLen: 12}, {Op: Deleted, Start: 3, Old: "\t\\", OldLen: 2}}
+		data, err := MarshalEdits(edits)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		var decoded []Edit
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(decoded, edits) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", edits, decoded)
+		}
+	})
+
+	// Test that no edits produce an empty array
+	t.Run("No edits", func(t *testing.T) {
+		data, err := MarshalEdits(nil)
+		if err != nil || string(data) != "[]" {
+			t.Errorf("Test failed. Expected: [] Got: %s %v", data, err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package textcompare
+
+import "fmt"
+
+// Position locates a rune of a text by its 1-based line and its 1-based
+// column, counted in runes from the start of the line.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// LineSpan is the part of the old text an edit covers. Start is where the
+// edit begins and End is the last rune it replaces, which is Start for an
+// addition. An edit replacing a newline ends on the line it terminates.
+type LineSpan struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// positionIn converts the rune index pos into the position it has in the
+// text split into tl. The end of a text terminated by a newline is the first
+// column of the line after the last one.
+func positionIn(tl textLines, pos int) Position {
+	line := tl.lineOf(pos)
+	return Position{Line: line + 1, Column: pos - tl.startOf(line) + 1}
+}
+
+// PositionOf returns the line and column of the rune at index pos of text.
+func PositionOf(text string, pos int) Position {
+	return positionIn(splitTextLines(text), pos)
+}
+
+// LineSpans returns the line and column span every edit covers in old, in the
+// order of the edits, so edits can be located by readers of a multi-line text.
+func LineSpans(old string, edits []Edit) []LineSpan {
+	tl := splitTextLines(old)
+	spans := make([]LineSpan, len(edits))
+	for i, edit := range edits {
+		spans[i] = lineSpanIn(tl, edit)
+	}
+	return spans
+}
+
+func lineSpanIn(tl textLines, edit Edit) LineSpan {
+	span := LineSpan{Start: positionIn(tl, edit.Start)}
+	span.End = span.Start
+	if edit.OldLen > 0 {
+		span.End = positionIn(tl, edit.Start+edit.OldLen-1)
+	}
+	return span
+}
+
+// FormatLineColumns renders edits in the textual delta format produced by
+// Diff, with every position given as a line and column of old instead of a
+// character index: "Line 2, column 5 Length: 3/5 [--- cat][+++ tiger]". An
+// edit spanning several lines also gives the line and column where it ends.
+// With color, deletions are red and additions green as in FormatColor. The
+// result is meant to be read, and cannot be parsed back by ParsePatch.
+func FormatLineColumns(old string, edits []Edit, color bool) string {
+	tl := splitTextLines(old)
+	position := func(edit Edit) string {
+		span := lineSpanIn(tl, edit)
+		if span.End.Line == span.Start.Line {
+			return fmt.Sprintf("Line %d, column %d", span.Start.Line, span.Start.Column)
+		}
+		return fmt.Sprintf("Line %d, column %d to line %d, column %d", span.Start.Line, span.Start.Column, span.End.Line, span.End.Column)
+	}
+	if color {
+		return formatDelta(edits, position, ansiRed, ansiGreen, ansiReset)
+	}
+	return formatDelta(edits, position, "", "", "")
+}
@@ -0,0 +1,70 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineSpans(t *testing.T) {
+	oldText := "first line\nsecond line\nthird line\n"
+
+	// Test the line and column of positions on every line
+	t.Run("Positions", func(t *testing.T) {
+		cases := []struct {
+			pos      int
+			expected Position
+		}{
+			{0, Position{1, 1}},
+			{10, Position{1, 11}},
+			{11, Position{2, 1}},
+			{18, Position{2, 8}},
+			{len(oldText), Position{4, 1}},
+		}
+		for _, c := range cases {
+			if got := PositionOf(oldText, c.pos); got != c.expected {
+				t.Errorf("Test failed. Position %d Expected: %+v Got: %+v", c.pos, c.expected, got)
+			}
+		}
+		if got := PositionOf("ab", 2); got != (Position{1, 3}) {
+			t.Errorf("Test failed. Expected: {1 3} Got: %+v", got)
+		}
+	})
+
+	// Test that edits found on different lines are located on them
+	t.Run("Edits on different lines", func(t *testing.T) {
+		updatedText := "first line\nsecond lane\nthird line!\n"
+		edits, err := DiffEdits(oldText, updatedText, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []LineSpan{
+			{Start: Position{2, 9}, End: Position{2, 9}},
+			{Start: Position{3, 11}, End: Position{3, 11}},
+		}
+		if got := LineSpans(oldText, edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that an edit crossing a line boundary ends on the next line
+	t.Run("Edit spanning lines", func(t *testing.T) {
+		edits := []Edit{newEdit(6, "line\nsec", "")}
+		expected := []LineSpan{{Start: Position{1, 7}, End: Position{2, 3}}}
+		if got := LineSpans(oldText, edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test the formatted output with line and column positions
+	t.Run("Formatted output", func(t *testing.T) {
+		edits := []Edit{newEdit(6, "line\nsec", ""), newEdit(30, "", "!")}
+		expected := "Line 1, column 7 to line 2, column 3 Length: 8/0 [--- line\\nsec]\nLine 3, column 8 Length: 0/1 [+++ !]\n"
+		if got := FormatLineColumns(oldText, edits, false); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+		if got := FormatLineColumns(oldText, edits, true); !strings.Contains(got, ansiRed) {
+			t.Errorf("Test failed. Expected: escape sequences Got: %q", got)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package textcompare
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// tokenTable assigns every distinct token a rune so that a sequence of tokens
+// can be compared by the rune based engine, one token per character.
+type tokenTable struct {
+	ids    map[string]rune
+	tokens []string
+}
+
+func newTokenTable() *tokenTable {
+	return &tokenTable{ids: make(map[string]rune)}
+}
+
+// encode returns the string holding one rune per token.
+func (tt *tokenTable) encode(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		id, ok := tt.ids[token]
+		if !ok {
+			id = tokenRune(len(tt.tokens))
+			tt.ids[token] = id
+			tt.tokens = append(tt.tokens, token)
+		}
+		sb.WriteRune(id)
+	}
+	return sb.String()
+}
+
+// decode maps the runes of an encoded string back to their tokens.
+func (tt *tokenTable) decode(s string) []string {
+	tokens := make([]string, 0, utf8.RuneCountInString(s))
+	for _, id := range s {
+		tokens = append(tokens, tt.tokens[tokenIndex(id)])
+	}
+	return tokens
+}
+
+// tokenRune maps the i-th distinct token to a valid rune, skipping the
+// surrogate range which cannot be stored in a string.
+func tokenRune(i int) rune {
+	if i >= 0xD800 {
+		i += 0x800
+	}
+	return rune(i)
+}
+
+func tokenIndex(r rune) int {
+	i := int(r)
+	if i >= 0xE000 {
+		i -= 0x800
+	}
+	return i
+}
+
+// compareTokens compares two token sequences one token per character. The
+// edits returned hold the encoded tokens as content, with token indexes and
+// counts as positions and lengths, and are decoded with the table returned.
+func compareTokens(old, updated []string) ([]Edit, *tokenTable) {
+	table := newTokenTable()
+	encodedOld := table.encode(old)
+	encodedUpdated := table.encode(updated)
+	// A window of one token is always valid, so no error can be returned
+	edits, _ := newDiffer(DiffOptions{}).collectEdits(encodedOld, encodedUpdated, 1, 0)
+	return edits, table
+}
+
+// diffTokens compares two token sequences treating every token as an atomic
+// unit. Edit positions and lengths count tokens, and the content of each edit
+// is made of the affected tokens joined with sep.
+func diffTokens(old, updated []string, sep string) []Edit {
+	edits, table := compareTokens(old, updated)
+	for i, edit := range edits {
+		edits[i].Old = strings.Join(table.decode(edit.Old), sep)
+		edits[i].New = strings.Join(table.decode(edit.New), sep)
+		edits[i].WhitespaceOnly = whitespaceOnly(edits[i].Old, edits[i].New)
+	}
+	return edits
+}
+
+// DiffLines compares two documents line by line. Every line is treated as a
+// single unit, so a changed line is reported as a whole. Edit positions,
+// NewStart included, are 0-based line numbers, OldLen and NewLen count lines,
+// and the content of each edit holds the affected lines joined with "\n".
+func DiffLines(old, updated []string) []Edit {
+	return diffTokens(old, updated, "\n")
+}
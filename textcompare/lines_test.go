@@ -0,0 +1,83 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	// Test that lines are reported as whole units with their line numbers
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{
+			"Modified line",
+			"host=localhost\nport=8080\ndebug=false",
+			"host=localhost\nport=9090\ndebug=false",
+			[]Edit{{Op: Modified, Start: 1, NewStart: 1, Old: "port=8080", New: "port=9090", OldLen: 1, NewLen: 1}},
+		},
+		{
+			"Added lines at the end",
+			"host=localhost\nport=8080",
+			"host=localhost\nport=8080\ndebug=true\nverbose=true",
+			[]Edit{{Op: Added, Start: 2, NewStart: 2, New: "debug=true\nverbose=true", NewLen: 2}},
+		},
+		{
+			"Deleted line at the end",
+			"host=localhost\nport=8080\ndebug=false",
+			"host=localhost\nport=8080",
+			[]Edit{{Op: Deleted, Start: 2, NewStart: 2, Old: "debug=false", OldLen: 1}},
+		},
+		{
+			"No changes",
+			"host=localhost\nport=8080",
+			"host=localhost\nport=8080",
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := DiffLines(strings.Split(tt.oldText, "\n"), strings.Split(tt.updated, "\n"))
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+}
+
+func TestDiffLinesUnits(t *testing.T) {
+	// Test that every edit covers the lines from Start to Start+OldLen of old
+	old := []string{"a", "b", "c", "d", "e", "f"}
+	updated := []string{"a", "x", "y", "z", "d", "f", "g"}
+	for _, edit := range DiffLines(old, updated) {
+		if got := strings.Join(old[edit.Start:edit.Start+edit.OldLen], "\n"); got != edit.Old {
+			t.Errorf("Test failed. Expected: %q Got: %q", edit.Old, got)
+		}
+		if got := strings.Join(updated[edit.NewStart:edit.NewStart+edit.NewLen], "\n"); got != edit.New {
+			t.Errorf("Test failed. Expected: %q Got: %q", edit.New, got)
+		}
+	}
+
+	// Test that inverting the edits keeps them in lines, covering the lines of updated
+	for _, edit := range InvertEdits(DiffLines(old, updated)) {
+		if got := strings.Join(updated[edit.Start:edit.Start+edit.OldLen], "\n"); got != edit.Old {
+			t.Errorf("Test failed. Expected: %q Got: %q", edit.Old, got)
+		}
+		if got := strings.Join(old[edit.NewStart:edit.NewStart+edit.NewLen], "\n"); got != edit.New {
+			t.Errorf("Test failed. Expected: %q Got: %q", edit.New, got)
+		}
+	}
+}
+
+func TestTokenRune(t *testing.T) {
+	// Test that token ids survive the round trip around the surrogate range
+	for _, i := range []int{0, 0xD7FF, 0xD800, 0xF000} {
+		if got := tokenIndex(tokenRune(i)); got != i {
+			t.Errorf("Test failed. Expected: %d Got: %d", i, got)
+		}
+	}
+}
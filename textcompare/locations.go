@@ -0,0 +1,27 @@
+package textcompare
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Locations returns the position in the old text where every edit starts, in
+// the order of the edits.
+func Locations(edits []Edit) []int {
+	locations := make([]int, len(edits))
+	for i, edit := range edits {
+		locations[i] = edit.Start
+	}
+	return locations
+}
+
+// FormatLocations renders one "op:start:length" line per edit, where start is
+// the 0-based rune position of the edit in the old text and length the number
+// of old runes it replaces, which is 0 for an addition.
+func FormatLocations(edits []Edit) string {
+	var sb strings.Builder
+	for _, edit := range edits {
+		fmt.Fprintf(&sb, "%s:%d:%d\n", edit.Op, edit.Start, edit.OldLen)
+	}
+	return sb.String()
+}
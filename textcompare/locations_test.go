@@ -0,0 +1,40 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLocations(t *testing.T) {
+	oldText, updatedText := "the quick brown fox", "the quack brown fox jumps"
+	edits, err := DiffEdits(oldText, updatedText, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Test that the positions are the starts of the edits
+	t.Run("Positions match the edits", func(t *testing.T) {
+		expected := []int{6, 19}
+		if got := Locations(edits); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %v Got: %v", expected, got)
+		}
+	})
+
+	// Test that every edit becomes one op:start:length line
+	t.Run("Formatted locations", func(t *testing.T) {
+		expected := "modified:6:1\nadded:19:0\n"
+		if got := FormatLocations(edits); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that no edits give no locations
+	t.Run("No edits", func(t *testing.T) {
+		if got := Locations(nil); len(got) != 0 {
+			t.Errorf("Test failed. Expected: no locations Got: %v", got)
+		}
+		if got := FormatLocations(nil); got != "" {
+			t.Errorf("Test failed. Expected: an empty string Got: %q", got)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package textcompare
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Conflict is a region of the common ancestor that both sides of a merge
+// changed in different ways. Start is a 0-based rune index into the ancestor
+// and Base holds its original content there, while A and B hold what each side
+// replaced it with.
+type Conflict struct {
+	Start int    `json:"start"`
+	Base  string `json:"base"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// mergeEdit is an edit tagged with the side of the merge it comes from.
+type mergeEdit struct {
+	Edit
+	fromA bool
+}
+
+// end returns the position in the ancestor right after the replaced content.
+func (e mergeEdit) end() int {
+	return e.Start + utf8.RuneCountInString(e.Old)
+}
+
+// Merge combines the changes made from base to a and from base to b. Edits
+// to separate regions of base are all applied. Regions changed by both sides,
+// including changes right next to each other, are applied once when both made
+// the same change, and otherwise keep the base content and are reported as
+// conflicts.
+func Merge(base, a, b string, windowSize int) (merged string, conflicts []Conflict, err error) {
+	defer recoverPanic(&err)
+	editsA, err := DiffEdits(base, a, windowSize)
+	if err != nil {
+		return "", nil, err
+	}
+	editsB, err := DiffEdits(base, b, windowSize)
+	if err != nil {
+		return "", nil, err
+	}
+	var all []mergeEdit
+	for _, edit := range expandMoves(editsA) {
+		all = append(all, mergeEdit{edit, true})
+	}
+	for _, edit := range expandMoves(editsB) {
+		all = append(all, mergeEdit{edit, false})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start < all[j].Start })
+
+	baseRunes := []rune(base)
+	var sb strings.Builder
+	pos := 0
+	for i := 0; i < len(all); {
+		// Gather every edit overlapping or touching the region.
+		start, end := all[i].Start, all[i].end()
+		j := i + 1
+		for j < len(all) && all[j].Start <= end {
+			end = max(end, all[j].end())
+			j++
+		}
+		var sideA, sideB []Edit
+		for _, edit := range all[i:j] {
+			edit.Start -= start
+			if edit.fromA {
+				sideA = append(sideA, edit.Edit)
+			} else {
+				sideB = append(sideB, edit.Edit)
+			}
+		}
+		original := string(baseRunes[start:end])
+		resultA, resultB := applyEdits(original, sideA), applyEdits(original, sideB)
+
+		sb.WriteString(string(baseRunes[pos:start]))
+		switch {
+		case sideB == nil || resultA == resultB:
+			sb.WriteString(resultA)
+		case sideA == nil:
+			sb.WriteString(resultB)
+		default:
+			sb.WriteString(original)
+			conflicts = append(conflicts, Conflict{Start: start, Base: original, A: resultA, B: resultB})
+		}
+		pos = end
+		i = j
+	}
+	sb.WriteString(string(baseRunes[pos:]))
+	return sb.String(), conflicts, nil
+}
@@ -0,0 +1,78 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	// Test that edits to different regions are all applied
+	t.Run("Disjoint edits", func(t *testing.T) {
+		base := "the cat sat on the mat"
+		a := "the dog sat on the mat"
+		b := "the cat sat on the rug"
+		merged, conflicts, err := Merge(base, a, b, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := "the dog sat on the rug"
+		if merged != expected || len(conflicts) != 0 {
+			t.Errorf("Test failed. Expected: %s Got: %s with conflicts %+v", expected, merged, conflicts)
+		}
+	})
+
+	// Test that an addition on one side merges with a deletion on the other
+	t.Run("Addition and deletion", func(t *testing.T) {
+		merged, conflicts, err := Merge("hello world", "hello world again", "world", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := "world again"
+		if merged != expected || len(conflicts) != 0 {
+			t.Errorf("Test failed. Expected: %s Got: %s with conflicts %+v", expected, merged, conflicts)
+		}
+	})
+
+	// Test that the same change made on both sides is applied once
+	t.Run("Identical edits", func(t *testing.T) {
+		merged, conflicts, err := Merge("hello world", "hello there", "hello there", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if merged != "hello there" || len(conflicts) != 0 {
+			t.Errorf("Test failed. Expected: %s Got: %s with conflicts %+v", "hello there", merged, conflicts)
+		}
+	})
+
+	// Test that both sides changing the same region is a conflict
+	t.Run("Conflicting edits", func(t *testing.T) {
+		base := "one two three"
+		merged, conflicts, err := Merge(base, "one TWO three", "one dos three", 1)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(conflicts) != 1 {
+			t.Fatalf("Test failed. Expected: 1 conflict Got: %+v", conflicts)
+		}
+		conflict := conflicts[0]
+		got := base[:conflict.Start] + conflict.A + base[conflict.Start+len(conflict.Base):]
+		if got != "one TWO three" {
+			t.Errorf("Test failed. Expected: %s Got: %s", "one TWO three", got)
+		}
+		got = base[:conflict.Start] + conflict.B + base[conflict.Start+len(conflict.Base):]
+		if got != "one dos three" {
+			t.Errorf("Test failed. Expected: %s Got: %s", "one dos three", got)
+		}
+		if merged != base {
+			t.Errorf("Test failed. Expected: %s Got: %s", base, merged)
+		}
+	})
+
+	// Test that an invalid window size is reported
+	t.Run("Invalid window", func(t *testing.T) {
+		_, conflicts, err := Merge("a", "b", "c", 0)
+		if err == nil || !reflect.DeepEqual(conflicts, []Conflict(nil)) {
+			t.Errorf("Test failed. Expected: an error Got: %v", err)
+		}
+	})
+}
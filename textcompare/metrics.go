@@ -0,0 +1,176 @@
+package textcompare
+
+import "unicode/utf8"
+
+// changedChars returns the number of characters touched by an edit. A
+// modification counts the longest of its two sides.
+func changedChars(edit Edit) int {
+	return max(utf8.RuneCountInString(edit.Old), utf8.RuneCountInString(edit.New))
+}
+
+// Similarity returns how alike two texts are, from 1.0 for identical texts
+// down to 0.0 for texts without anything in common. It is computed as
+// 1 - changed/max(len(old), len(updated)), counting characters as runes.
+// Invalid input, such as a non-positive window size, scores 0.0.
+func Similarity(old, updated string, windowSize int) float64 {
+	longest := max(utf8.RuneCountInString(old), utf8.RuneCountInString(updated))
+	if longest == 0 {
+		return 1
+	}
+	edits, err := DiffEdits(old, updated, windowSize)
+	if err != nil {
+		return 0
+	}
+	changed := ChangedChars(edits)
+	return max(0, 1-float64(changed)/float64(longest))
+}
+
+// ClosestMatch returns the index of the candidate most similar to target,
+// as scored by Similarity, and its score. The first of equally similar
+// candidates wins, and the search stops at a candidate equal to target. No
+// candidates give an index of -1 and a score of 0.
+func ClosestMatch(target string, candidates []string, windowSize int) (index int, score float64) {
+	index = -1
+	for i, candidate := range candidates {
+		if candidate == target {
+			return i, 1
+		}
+		if similarity := Similarity(target, candidate, windowSize); index < 0 || similarity > score {
+			index, score = i, similarity
+		}
+	}
+	return index, score
+}
+
+// EditDistance returns the number of characters deleted from old plus the
+// number of characters added to reach updated, as found by DiffEdits. Every
+// modified character is counted twice, once deleted and once added, unlike
+// ChangedChars which counts the longest side of a modification, so texts
+// without anything in common are len(old)+len(updated) apart. Invalid input,
+// such as a non-positive window size, gives -1.
+func EditDistance(old, updated string, windowSize int) int {
+	d := newDiffer(DiffOptions{})
+	d.countOnly = true
+	edits, err := d.collectEdits(old, updated, windowSize, 0)
+	if err != nil {
+		return -1
+	}
+	distance := 0
+	for _, edit := range edits {
+		distance += edit.OldLen + edit.NewLen
+	}
+	return distance
+}
+
+// Summarize counts edits by kind. A moved block counts as one deletion and
+// one addition.
+func Summarize(edits []Edit) (added, deleted, modified int) {
+	for _, edit := range expandMoves(edits) {
+		switch edit.Op {
+		case Added:
+			added++
+		case Deleted:
+			deleted++
+		case Modified:
+			modified++
+		}
+	}
+	return added, deleted, modified
+}
+
+// ChangedChars returns the total number of characters touched by edits,
+// counting the longest side of each modification.
+func ChangedChars(edits []Edit) int {
+	changed := 0
+	for _, edit := range expandMoves(edits) {
+		changed += changedChars(edit)
+	}
+	return changed
+}
+
+// ChangeCounts holds the number of edits of each kind between two texts and
+// the number of characters they touch, counted like ChangedChars.
+type ChangeCounts struct {
+	Added        int
+	Deleted      int
+	Modified     int
+	ChangedChars int
+}
+
+// DiffCounts compares old against updated like DiffEdits but only counts the
+// edits, without building their content.
+func DiffCounts(old, updated string, windowSize int) (counts ChangeCounts, err error) {
+	defer recoverPanic(&err)
+	d := newDiffer(DiffOptions{})
+	d.countOnly = true
+	edits, err := d.collectEdits(old, updated, windowSize, 0)
+	if err != nil {
+		return ChangeCounts{}, err
+	}
+	for _, edit := range edits {
+		switch edit.Op {
+		case Added:
+			counts.Added++
+		case Deleted:
+			counts.Deleted++
+		case Modified:
+			counts.Modified++
+		}
+		counts.ChangedChars += max(edit.OldLen, edit.NewLen)
+	}
+	return counts, nil
+}
+
+// Relation classifies how an updated text relates to the old one.
+type Relation int
+
+const (
+	// RelationIdentical means both texts are equal.
+	RelationIdentical Relation = iota
+	// RelationAdded means the updated text only adds content.
+	RelationAdded
+	// RelationDeleted means the updated text only removes content.
+	RelationDeleted
+	// RelationModified means the updated text only replaces content.
+	RelationModified
+	// RelationMixed means the updated text combines several kinds of edits.
+	RelationMixed
+)
+
+// String returns the lower case name of the relation.
+func (r Relation) String() string {
+	switch r {
+	case RelationIdentical:
+		return "identical"
+	case RelationAdded:
+		return "added"
+	case RelationDeleted:
+		return "deleted"
+	case RelationModified:
+		return "modified"
+	case RelationMixed:
+		return "mixed"
+	}
+	return "unknown"
+}
+
+// Compare classifies the relation between a and its updated version b from
+// the kinds of the edits between them, compared with the window size
+// suggested by SuggestWindowSize.
+func Compare(a, b string) Relation {
+	// The suggested window size is always valid, so the comparison cannot fail
+	edits, _ := DiffEdits(a, b, SuggestWindowSize(a, b))
+	if len(edits) == 0 {
+		return RelationIdentical
+	}
+	added, deleted, modified := Summarize(edits)
+	switch {
+	case deleted == 0 && modified == 0:
+		return RelationAdded
+	case added == 0 && modified == 0:
+		return RelationDeleted
+	case added == 0 && deleted == 0:
+		return RelationModified
+	}
+	return RelationMixed
+}
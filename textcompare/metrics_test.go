@@ -0,0 +1,233 @@
+package textcompare
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected float64
+	}{
+		{"Identical texts", "hello world", "hello world", 1},
+		{"Empty texts", "", "", 1},
+		{"Disjoint texts", "abcdef", "uvwxyz", 0},
+		{"One modified character", "hello world", "hello xorld", 1 - 1.0/11},
+		{"Added content", "hello", "hello world", 5.0 / 11},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.oldText, tt.updated, 2)
+			if math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("Test failed. Expected: %f Got: %f", tt.expected, got)
+			}
+		})
+	}
+
+	// Test that invalid input scores zero
+	t.Run("Invalid window", func(t *testing.T) {
+		if got := Similarity("hello", "jello", 0); got != 0 {
+			t.Errorf("Test failed. Expected: 0 Got: %f", got)
+		}
+	})
+}
+
+func TestClosestMatch(t *testing.T) {
+	candidates := []string{"apple pie", "banana split", "apple tart", "cherry pie"}
+	tests := []struct {
+		name          string
+		target        string
+		expectedIndex int
+	}{
+		{"Close spelling", "aple pie", 0},
+		{"Shared words", "banana splits", 1},
+		{"Other dessert", "cherry tart", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, score := ClosestMatch(tt.target, candidates, 2)
+			if index != tt.expectedIndex {
+				t.Errorf("Test failed. Expected: %d Got: %d", tt.expectedIndex, index)
+			}
+			if expected := Similarity(tt.target, candidates[tt.expectedIndex], 2); score != expected {
+				t.Errorf("Test failed. Expected: %f Got: %f", expected, score)
+			}
+		})
+	}
+
+	// Test that an exact match scores 1 and wins over later candidates
+	t.Run("Exact match", func(t *testing.T) {
+		if index, score := ClosestMatch("apple tart", append(candidates, "apple tart"), 2); index != 2 || score != 1 {
+			t.Errorf("Test failed. Expected: 2 1 Got: %d %f", index, score)
+		}
+	})
+
+	// Test that no candidates give no match
+	t.Run("No candidates", func(t *testing.T) {
+		if index, score := ClosestMatch("apple", nil, 2); index != -1 || score != 0 {
+			t.Errorf("Test failed. Expected: -1 0 Got: %d %f", index, score)
+		}
+	})
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected int
+	}{
+		{"Identical texts", "hello world", "hello world", 0},
+		{"Empty texts", "", "", 0},
+		{"Added content", "hello", "hello world", 6},
+		{"Deleted content", "hello world", "hello", 6},
+		{"One modified character", "hello world", "hello xorld", 2},
+		{"Disjoint texts", "abc", "uvwxyz", 9},
+		{"Multibyte characters", "año", "ano", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EditDistance(tt.oldText, tt.updated, 2); got != tt.expected {
+				t.Errorf("Test failed. Expected: %d Got: %d", tt.expected, got)
+			}
+		})
+	}
+
+	// Test that the distance counts both sides of the edits found by DiffEdits
+	t.Run("Matches DiffEdits", func(t *testing.T) {
+		edits, _ := DiffEdits("the cat sat", "a dog sat down", 2)
+		expected := 0
+		for _, edit := range edits {
+			expected += edit.OldLen + edit.NewLen
+		}
+		if got := EditDistance("the cat sat", "a dog sat down", 2); got != expected {
+			t.Errorf("Test failed. Expected: %d Got: %d", expected, got)
+		}
+	})
+
+	// Test that invalid input gives -1
+	t.Run("Invalid window", func(t *testing.T) {
+		if got := EditDistance("hello", "jello", 0); got != -1 {
+			t.Errorf("Test failed. Expected: -1 Got: %d", got)
+		}
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	edits := []Edit{
+		{Op: Added, Start: 0, New: "new ", NewLen: 4},
+		{Op: Modified, Start: 4, Old: "cat", New: "dog", OldLen: 3, NewLen: 3},
+		{Op: Deleted, Start: 10, Old: "xy", OldLen: 2},
+		{Op: Added, Start: 14, New: "!", NewLen: 1},
+		{Op: Modified, Start: 20, Old: "a", New: "bcd", OldLen: 1, NewLen: 3},
+	}
+
+	// Test that every kind of edit is counted
+	t.Run("Counts", func(t *testing.T) {
+		added, deleted, modified := Summarize(edits)
+		if added != 2 || deleted != 1 || modified != 2 {
+			t.Errorf("Test failed. Expected: 2 1 2 Got: %d %d %d", added, deleted, modified)
+		}
+	})
+
+	// Test that the changed characters add up across edits
+	t.Run("Changed characters", func(t *testing.T) {
+		if got := ChangedChars(edits); got != 13 {
+			t.Errorf("Test failed. Expected: %d Got: %d", 13, got)
+		}
+	})
+
+	// Test that a move counts as a deletion and an addition
+	t.Run("Moves", func(t *testing.T) {
+		added, deleted, modified := Summarize([]Edit{{Op: Moved, Start: 0, Old: "ab", New: "ab", OldLen: 2, NewLen: 2, To: 5}})
+		if added != 1 || deleted != 1 || modified != 0 {
+			t.Errorf("Test failed. Expected: 1 1 0 Got: %d %d %d", added, deleted, modified)
+		}
+	})
+
+	// Test that no edits summarize to zero
+	t.Run("Empty", func(t *testing.T) {
+		added, deleted, modified := Summarize(nil)
+		if added+deleted+modified != 0 || ChangedChars(nil) != 0 {
+			t.Errorf("Test failed. Expected: 0 0 0 Got: %d %d %d", added, deleted, modified)
+		}
+	})
+}
+
+func TestDiffCounts(t *testing.T) {
+	// Test that the counts match the edits built with their content
+	tests := [][2]string{
+		{"hello world", "hello there world"},
+		{"the quick brown fox", "a quick red fox jumps"},
+		{"abcdef", "uvwxyz"},
+		{"hello world", "hello world"},
+		{strings.Repeat("ab", 50), strings.Repeat("ax", 50)},
+	}
+	for _, tt := range tests {
+		edits, err := DiffEdits(tt[0], tt[1], 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		added, deleted, modified := Summarize(edits)
+		expected := ChangeCounts{Added: added, Deleted: deleted, Modified: modified, ChangedChars: ChangedChars(edits)}
+		counts, err := DiffCounts(tt[0], tt[1], 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if counts != expected {
+			t.Errorf("Test failed. %q Expected: %+v Got: %+v", tt, expected, counts)
+		}
+	}
+
+	// Test that invalid windows are rejected
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, err := DiffCounts("a", "b", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func manyChanges() (string, string) {
+	return strings.Repeat("lorem ipsum dolor sit amet ", 40), strings.Repeat("lorem ipsum dolor sat amet ", 40)
+}
+
+func BenchmarkDiffEditsManyChanges(b *testing.B) {
+	old, updated := manyChanges()
+	for i := 0; i < b.N; i++ {
+		DiffEdits(old, updated, 4)
+	}
+}
+
+func BenchmarkDiffCountsManyChanges(b *testing.B) {
+	old, updated := manyChanges()
+	for i := 0; i < b.N; i++ {
+		DiffCounts(old, updated, 4)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Test every category of relation between two texts
+	tests := []struct {
+		name     string
+		a, b     string
+		expected Relation
+	}{
+		{"Identical", "hello world", "hello world", RelationIdentical},
+		{"Both empty", "", "", RelationIdentical},
+		{"Added", "hello world", "hello brave new world", RelationAdded},
+		{"Deleted", "hello brave new world", "hello world", RelationDeleted},
+		{"Modified", "hello world", "hello there", RelationModified},
+		{"Mixed", "the cat sat on the mat", "a cat sat down on the mat!", RelationMixed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.expected {
+				t.Errorf("Test failed. Expected: %s Got: %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package textcompare
+
+import "slices"
+
+// scriptStep is one step of an edit script: keeping a character shared by
+// both texts, deleting one from the old text or inserting one from the new.
+type scriptStep int
+
+const (
+	stepKeep scriptStep = iota
+	stepDelete
+	stepInsert
+)
+
+// shortestEditScript returns a shortest sequence of steps turning a into b,
+// found with Myers' O(ND) algorithm.
+func shortestEditScript(a, b []rune) []scriptStep {
+	n, m := len(a), len(b)
+	limit := n + m
+	v := make([]int, 2*limit+2)
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+				x = v[limit+k+1]
+			} else {
+				x = v[limit+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[limit+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	// Walk the trace backwards from the end of both texts
+	var steps []scriptStep
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[limit+k-1] < v[limit+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[limit+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			steps = append(steps, stepKeep)
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				steps = append(steps, stepInsert)
+			} else {
+				steps = append(steps, stepDelete)
+			}
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(steps)
+	return steps
+}
+
+// DiffMinimal compares old against updated with a shortest edit script
+// instead of the rolling hash search, so the edits touch as few characters as
+// possible even on repetitive texts. It is slower and uses more memory than
+// DiffEdits on large texts with many differences. Every run of changes between
+// shared characters is reported as a single edit.
+func DiffMinimal(old, updated string) []Edit {
+	oldRunes, newRunes := []rune(old), []rune(updated)
+	var edits []Edit
+	x, y := 0, 0
+	startX, startY := 0, 0
+	flush := func() {
+		edit := newEdit(startX, string(oldRunes[startX:x]), string(newRunes[startY:y]))
+		edit.NewStart = startY
+		edits = appendEdit(edits, edit)
+	}
+	for _, step := range shortestEditScript(oldRunes, newRunes) {
+		switch step {
+		case stepKeep:
+			flush()
+			x++
+			y++
+			startX, startY = x, y
+		case stepDelete:
+			x++
+		case stepInsert:
+			y++
+		}
+	}
+	flush()
+	return edits
+}
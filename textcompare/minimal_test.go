@@ -0,0 +1,93 @@
+package textcompare
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestDiffMinimal(t *testing.T) {
+	// Test the edits of simple comparisons
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{"Identical texts", "hello", "hello", nil},
+		{"Empty texts", "", "", nil},
+		{"Added content", "hello", "hello world", []Edit{{Op: Added, Start: 5, NewStart: 5, New: " world", NewLen: 6}}},
+		{"Deleted content", "hello world", "world", []Edit{{Op: Deleted, Start: 0, NewStart: 0, Old: "hello ", OldLen: 6}}},
+		{"Inserted in the middle", "abXcd", "abYXcd", []Edit{{Op: Added, Start: 2, NewStart: 2, New: "Y", NewLen: 1}}},
+		{"Two replacements", "the cat sat on the mat", "the dog sat on a mat", []Edit{
+			{Op: Modified, Start: 4, NewStart: 4, Old: "cat", New: "dog", OldLen: 3, NewLen: 3},
+			{Op: Modified, Start: 15, NewStart: 15, Old: "the", New: "a", OldLen: 3, NewLen: 1},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffMinimal(tt.oldText, tt.updated); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, got)
+			}
+		})
+	}
+
+	// Test that repetitive texts change no more characters than with the
+	// rolling hash, which may cover a long region with a single edit
+	t.Run("Repetitive texts", func(t *testing.T) {
+		pairs := [][2]string{
+			{"abababababab", "babababababa"},
+			{"aaaabaaaa", "aaaaaaaab"},
+			{"the cat sat on the mat", "the dog sat on a mat"},
+			{"one two three", "one 2 three"},
+		}
+		// Two separate replacements are two edits
+		rolling, _ := DiffEdits("the cat sat on the mat", "the dog sat on a mat", 2)
+		if minimal := DiffMinimal("the cat sat on the mat", "the dog sat on a mat"); len(minimal) != 2 || len(minimal) > len(rolling) {
+			t.Errorf("Test failed. Expected: 2 edits, no more than %d Got: %d", len(rolling), len(minimal))
+		}
+		for _, pair := range pairs {
+			rolling, err := DiffEdits(pair[0], pair[1], 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			minimal := DiffMinimal(pair[0], pair[1])
+			if ChangedChars(minimal) > ChangedChars(rolling) {
+				t.Errorf("Test failed. %q Expected: at most %d changed characters Got: %+v", pair, ChangedChars(rolling), minimal)
+			}
+		}
+	})
+
+	// Test that the edits rebuild the updated text
+	t.Run("Round trip", func(t *testing.T) {
+		r := rand.New(rand.NewSource(3))
+		alphabet := []rune("ab cñ")
+		randomText := func() string {
+			text := make([]rune, r.Intn(16))
+			for i := range text {
+				text[i] = alphabet[r.Intn(len(alphabet))]
+			}
+			return string(text)
+		}
+		for i := 0; i < 1000; i++ {
+			oldText, updatedText := randomText(), randomText()
+			edits := DiffMinimal(oldText, updatedText)
+			if got := applyEdits(oldText, edits); got != updatedText {
+				t.Fatalf("Test failed. Old: %q Updated: %q Edits: %+v Got: %q", oldText, updatedText, edits, got)
+			}
+		}
+	})
+
+	// Test that the algorithm can be selected through the options
+	t.Run("Algorithm option", func(t *testing.T) {
+		opts := DiffOptions{WindowSize: 2, Algorithm: AlgorithmMinimal, Whitespace: WhitespaceCollapse}
+		got, err := DiffWithOptions("the  cat sat", "the dog sat", opts)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 5, NewStart: 4, Old: "cat", New: "dog", OldLen: 3, NewLen: 3}}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package textcompare
+
+// DiffMulti compares every version with the next one using DiffEdits, and
+// returns the edits of each step: the edits at index i turn versions[i] into
+// versions[i+1]. Fewer than two versions give no steps.
+func DiffMulti(versions []string, windowSize int) ([][]Edit, error) {
+	if len(versions) < 2 {
+		return nil, nil
+	}
+	steps := make([][]Edit, len(versions)-1)
+	for i := range steps {
+		edits, err := DiffEdits(versions[i], versions[i+1], windowSize)
+		if err != nil {
+			return nil, err
+		}
+		steps[i] = edits
+	}
+	return steps, nil
+}
+
+// Churn describes the changes of one step of a DiffMulti comparison, and the
+// changes accumulated from the first version up to that step. Characters are
+// counted like ChangedChars.
+type Churn struct {
+	Edits             int
+	ChangedChars      int
+	TotalEdits        int
+	TotalChangedChars int
+}
+
+// SummarizeChurn returns the Churn of every step returned by DiffMulti, in
+// the same order, showing how changes accumulate across the versions.
+func SummarizeChurn(steps [][]Edit) []Churn {
+	churn := make([]Churn, len(steps))
+	totalEdits, totalChanged := 0, 0
+	for i, edits := range steps {
+		changed := ChangedChars(edits)
+		totalEdits += len(edits)
+		totalChanged += changed
+		churn[i] = Churn{Edits: len(edits), ChangedChars: changed, TotalEdits: totalEdits, TotalChangedChars: totalChanged}
+	}
+	return churn
+}
@@ -0,0 +1,52 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffMulti(t *testing.T) {
+	versions := []string{"the cat sat", "the cat sat down", "the dog sat down"}
+
+	// Test that every step matches the pairwise comparison of consecutive versions
+	t.Run("Pairwise results", func(t *testing.T) {
+		steps, err := DiffMulti(versions, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(steps) != 2 {
+			t.Fatalf("Test failed. Expected: 2 steps Got: %d", len(steps))
+		}
+		for i, edits := range steps {
+			expected, _ := DiffEdits(versions[i], versions[i+1], 2)
+			if !reflect.DeepEqual(edits, expected) {
+				t.Errorf("Test failed. Step %d Expected: %+v Got: %+v", i, expected, edits)
+			}
+			if got := applyEdits(versions[i], edits); got != versions[i+1] {
+				t.Errorf("Test failed. Expected: %s Got: %s", versions[i+1], got)
+			}
+		}
+	})
+
+	// Test that the churn of every step accumulates across the versions
+	t.Run("Churn summary", func(t *testing.T) {
+		steps, _ := DiffMulti(versions, 2)
+		expected := []Churn{
+			{Edits: 1, ChangedChars: 5, TotalEdits: 1, TotalChangedChars: 5},
+			{Edits: 1, ChangedChars: 3, TotalEdits: 2, TotalChangedChars: 8},
+		}
+		if got := SummarizeChurn(steps); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+
+	// Test that a single version has no steps and invalid windows are reported
+	t.Run("Edge cases", func(t *testing.T) {
+		if steps, err := DiffMulti(versions[:1], 2); err != nil || len(steps) != 0 {
+			t.Errorf("Test failed. Expected: no steps Got: %+v, %v", steps, err)
+		}
+		if _, err := DiffMulti(versions, 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package textcompare
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// normalizedText is a text rewritten before comparison. It remembers, for
+// every rune of the rewritten text, the index of the original rune it comes
+// from, so that edits found on the rewritten text can be reported against the
+// original one.
+type normalizedText struct {
+	original []rune
+	runes    []rune
+	// origin[i] is the index in original of runes[i], and
+	// origin[len(runes)] is len(original).
+	origin []int
+}
+
+func newNormalizedText(text string) normalizedText {
+	original := []rune(text)
+	origin := make([]int, len(original)+1)
+	for i := range origin {
+		origin[i] = i
+	}
+	return normalizedText{original: original, runes: original, origin: origin}
+}
+
+// rewrite builds a new normalized text by calling emit for the runes of nt.
+// emit receives the position of the rune in nt and appends the runes that
+// replace it through keep.
+func (nt normalizedText) rewrite(emit func(i int, keep func(r rune))) normalizedText {
+	result := normalizedText{original: nt.original}
+	for i := range nt.runes {
+		emit(i, func(r rune) {
+			result.runes = append(result.runes, r)
+			result.origin = append(result.origin, nt.origin[i])
+		})
+	}
+	result.origin = append(result.origin, len(nt.original))
+	return result
+}
+
+// maskRune replaces the content matched by the IgnorePatterns option, so that
+// any two matches compare equal.
+const maskRune = '\uFFFC'
+
+// mask replaces every non-empty match of pattern by a single maskRune.
+func (nt normalizedText) mask(pattern *regexp.Regexp) normalizedText {
+	text := string(nt.runes)
+	// masked[i] is 1 for the first rune of a match and 2 for the rest of it
+	masked := make([]byte, len(nt.runes))
+	for _, match := range pattern.FindAllStringIndex(text, -1) {
+		if match[0] == match[1] {
+			continue
+		}
+		start := utf8.RuneCountInString(text[:match[0]])
+		end := start + utf8.RuneCountInString(text[match[0]:match[1]])
+		masked[start] = 1
+		for i := start + 1; i < end; i++ {
+			masked[i] = 2
+		}
+	}
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		switch masked[i] {
+		case 0:
+			keep(nt.runes[i])
+		case 1:
+			keep(maskRune)
+		}
+	})
+}
+
+// normalizeNewlines drops the carriage return of every "\r\n" line ending.
+func (nt normalizedText) normalizeNewlines() normalizedText {
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		if nt.runes[i] != '\r' || i+1 == len(nt.runes) || nt.runes[i+1] != '\n' {
+			keep(nt.runes[i])
+		}
+	})
+}
+
+// expandTabs replaces every tab by the spaces reaching the next tab stop,
+// with a stop every width columns, so indentation made of tabs compares equal
+// to the same indentation made of spaces.
+func (nt normalizedText) expandTabs(width int) normalizedText {
+	column := 0
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		switch r := nt.runes[i]; r {
+		case '\t':
+			for next := (column/width + 1) * width; column < next; column++ {
+				keep(' ')
+			}
+		case '\n':
+			keep(r)
+			column = 0
+		default:
+			keep(r)
+			column++
+		}
+	})
+}
+
+// removeRunes drops every character found in set.
+func (nt normalizedText) removeRunes(set string) normalizedText {
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		if !strings.ContainsRune(set, nt.runes[i]) {
+			keep(nt.runes[i])
+		}
+	})
+}
+
+// removeWhitespace drops every whitespace character.
+func (nt normalizedText) removeWhitespace() normalizedText {
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		if !unicode.IsSpace(nt.runes[i]) {
+			keep(nt.runes[i])
+		}
+	})
+}
+
+// collapseWhitespace replaces every run of whitespace by a single space.
+func (nt normalizedText) collapseWhitespace() normalizedText {
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		switch {
+		case !unicode.IsSpace(nt.runes[i]):
+			keep(nt.runes[i])
+		case i == 0 || !unicode.IsSpace(nt.runes[i-1]):
+			keep(' ')
+		}
+	})
+}
+
+// foldCase replaces every character by its case folded form, lower case with
+// the special mappings of special and "ß" expanded to "ss", so that texts
+// differing only in case compare equal.
+func (nt normalizedText) foldCase(special unicode.SpecialCase) normalizedText {
+	return nt.rewrite(func(i int, keep func(r rune)) {
+		switch r := nt.runes[i]; r {
+		case 'ß', 'ẞ':
+			keep('s')
+			keep('s')
+		case 'ς':
+			keep('σ')
+		default:
+			keep(special.ToLower(r))
+		}
+	})
+}
+
+// caseMapping returns the special case mappings of the language with the
+// given BCP 47 tag, nil when the language follows the default mappings.
+func caseMapping(language string) unicode.SpecialCase {
+	primary, _, _ := strings.Cut(strings.ToLower(language), "-")
+	switch primary {
+	case "tr", "az":
+		return unicode.TurkishCase
+	}
+	return nil
+}
+
+// span returns the original content covered by the runes [from, to) of the
+// normalized text, and the original index where it starts.
+func (nt normalizedText) span(from, to int) (int, string) {
+	start, end := nt.origin[from], nt.origin[to]
+	return start, string(nt.original[start:end])
+}
+
+// expandedBefore returns how many runes before i come from the same original
+// character as the rune at i, when i falls inside the runes that character
+// was expanded to.
+func (nt normalizedText) expandedBefore(i int) int {
+	n := 0
+	for i-n > 0 && i < len(nt.runes) && nt.origin[i-n-1] == nt.origin[i] {
+		n++
+	}
+	return n
+}
+
+// expandedAfter returns how many runes from i come from the same original
+// character as the rune before i, when i falls inside the runes that
+// character was expanded to.
+func (nt normalizedText) expandedAfter(i int) int {
+	n := 0
+	for i > 0 && i+n < len(nt.runes) && nt.origin[i+n] == nt.origin[i-1] {
+		n++
+	}
+	return n
+}
+
+// mapEdits translates edits found between two normalized texts back to the
+// original texts. The content of each edit is taken from the original texts,
+// including any ignored characters inside the edit. An edit starting or
+// ending inside the runes an original character was expanded to, such as the
+// spaces of a tab or the "ss" of "ß", is widened on both sides to cover the
+// whole character, as the runes it grows by are equal in both texts, and
+// edits meeting once widened are merged.
+func mapEdits(edits []Edit, old, updated normalizedText) []Edit {
+	type region struct{ oldStart, oldEnd, newStart, newEnd int }
+	var regions []region
+	offset := 0
+	for _, edit := range edits {
+		r := region{oldStart: edit.Start, oldEnd: edit.Start + len([]rune(edit.Old)), newStart: edit.Start + offset}
+		r.newEnd = r.newStart + len([]rune(edit.New))
+		offset = r.newEnd - r.oldEnd
+		for {
+			before := max(old.expandedBefore(r.oldStart), updated.expandedBefore(r.newStart))
+			after := max(old.expandedAfter(r.oldEnd), updated.expandedAfter(r.newEnd))
+			if before == 0 && after == 0 {
+				break
+			}
+			r.oldStart, r.newStart = r.oldStart-before, r.newStart-before
+			r.oldEnd, r.newEnd = r.oldEnd+after, r.newEnd+after
+		}
+		if last := len(regions) - 1; last >= 0 && r.oldStart <= regions[last].oldEnd {
+			regions[last].oldEnd, regions[last].newEnd = r.oldEnd, r.newEnd
+			continue
+		}
+		regions = append(regions, r)
+	}
+	mapped := make([]Edit, 0, len(regions))
+	for _, r := range regions {
+		start, previous := old.span(r.oldStart, r.oldEnd)
+		updatedStart, next := updated.span(r.newStart, r.newEnd)
+		mappedEdit := newEdit(start, previous, next)
+		mappedEdit.NewStart = updatedStart
+		mapped = appendEdit(mapped, mappedEdit)
+	}
+	return mapped
+}
@@ -0,0 +1,389 @@
+package textcompare
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestWhitespaceModes(t *testing.T) {
+	// Test that runs of whitespace of different length compare equal
+	t.Run("Collapsed spaces", func(t *testing.T) {
+		for _, mode := range []WhitespaceMode{WhitespaceIgnore, WhitespaceCollapse} {
+			edits, err := DiffWithOptions("a  b", "a b", DiffOptions{WindowSize: 1, Whitespace: mode})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if len(edits) != 0 {
+				t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+			}
+		}
+	})
+
+	// Test that the exact mode still reports whitespace changes
+	t.Run("Exact", func(t *testing.T) {
+		edits, err := DiffWithOptions("a  b", "a b", DiffOptions{WindowSize: 1})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 {
+			t.Errorf("Test failed. Expected: edits Got: none")
+		}
+	})
+
+	// Test that reindenting with spaces instead of tabs is not a change
+	t.Run("Tabs to spaces", func(t *testing.T) {
+		old := "func f() {\n\tif x {\n\t\treturn\n\t}\n}\n"
+		updated := "func f() {\n    if x {\n        return\n    }\n}\n"
+		for _, mode := range []WhitespaceMode{WhitespaceIgnore, WhitespaceCollapse} {
+			edits, err := DiffWithOptions(old, updated, DiffOptions{WindowSize: 2, Whitespace: mode})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if len(edits) != 0 {
+				t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+			}
+		}
+	})
+
+	// Test that collapsing keeps the difference between some and no whitespace
+	t.Run("Collapse keeps separators", func(t *testing.T) {
+		edits, err := DiffWithOptions("ab", "a\t b", DiffOptions{WindowSize: 1, Whitespace: WhitespaceCollapse})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		ignored, err := DiffWithOptions("ab", "a\t b", DiffOptions{WindowSize: 1, Whitespace: WhitespaceIgnore})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 || len(ignored) != 0 {
+			t.Errorf("Test failed. Expected: edits only when collapsing Got: %+v and %+v", edits, ignored)
+		}
+	})
+
+	// Test that edits are reported with original positions and content
+	t.Run("Original positions", func(t *testing.T) {
+		old := "one  two\tthree"
+		updated := "one two  four"
+		edits, err := DiffWithOptions(old, updated, DiffOptions{WindowSize: 1, Whitespace: WhitespaceCollapse})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 || edits[0].Start != 9 {
+			t.Fatalf("Test failed. Expected: first edit at 9 Got: %+v", edits)
+		}
+		if got := applyEdits(old, edits); got != "one  two\tfour" {
+			t.Errorf("Test failed. Expected: %q Got: %q", "one  two\tfour", got)
+		}
+	})
+}
+
+func TestIgnorePunctuation(t *testing.T) {
+	// Test that punctuation changes are ignored
+	t.Run("Default set", func(t *testing.T) {
+		edits, err := DiffWithOptions("Hello, world!", "Hello world", DiffOptions{WindowSize: 2, IgnorePunctuation: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that the option is off by default
+	t.Run("Disabled", func(t *testing.T) {
+		edits, err := DiffWithOptions("Hello, world!", "Hello world", DiffOptions{WindowSize: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 {
+			t.Errorf("Test failed. Expected: edits Got: none")
+		}
+	})
+
+	// Test that a custom set replaces the default one
+	t.Run("Custom set", func(t *testing.T) {
+		opts := DiffOptions{WindowSize: 2, IgnorePunctuation: true, Punctuation: "!"}
+		edits, err := DiffWithOptions("Hello world!", "Hello world", opts)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+		edits, err = DiffWithOptions("Hello, world", "Hello world", opts)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 {
+			t.Errorf("Test failed. Expected: edits Got: none")
+		}
+	})
+
+	// Test that edits keep their original positions and content
+	t.Run("Original positions", func(t *testing.T) {
+		old := "Hello, world!"
+		updated := "Hello world?!, friends"
+		edits, err := DiffWithOptions(old, updated, DiffOptions{WindowSize: 1, IgnorePunctuation: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Added, Start: 13, NewStart: 14, New: " friends", NewLen: 8}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that it combines with the whitespace modes
+	t.Run("With whitespace", func(t *testing.T) {
+		opts := DiffOptions{WindowSize: 2, IgnorePunctuation: true, Whitespace: WhitespaceIgnore}
+		edits, err := DiffWithOptions("a, b; c.", "a b c", opts)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+}
+
+func TestNormalizeNewlines(t *testing.T) {
+	// Test that content differing only in line endings is equal
+	t.Run("Line endings only", func(t *testing.T) {
+		edits, err := DiffWithOptions("one\r\ntwo\r\nthree\r\n", "one\ntwo\nthree\n", DiffOptions{WindowSize: 2, NormalizeNewlines: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that line endings are compared by default
+	t.Run("Disabled", func(t *testing.T) {
+		edits, err := DiffWithOptions("one\r\ntwo\r\n", "one\ntwo\n", DiffOptions{WindowSize: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 {
+			t.Errorf("Test failed. Expected: edits Got: none")
+		}
+	})
+
+	// Test that a lone carriage return is still compared
+	t.Run("Lone carriage return", func(t *testing.T) {
+		edits, err := DiffWithOptions("one\rtwo", "one\ntwo", DiffOptions{WindowSize: 1, NormalizeNewlines: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 1 || edits[0].Old != "\r" {
+			t.Errorf("Test failed. Expected: the carriage return replaced Got: %+v", edits)
+		}
+	})
+
+	// Test that real changes keep their original positions and line endings
+	t.Run("Original positions", func(t *testing.T) {
+		old := "one\r\ntwo\r\nthree\r\n"
+		updated := "one\ntwo\nfour\n"
+		edits, err := DiffWithOptions(old, updated, DiffOptions{WindowSize: 1, NormalizeNewlines: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 || edits[0].Start != 10 || edits[0].NewStart != 8 {
+			t.Fatalf("Test failed. Expected: first edit at 10 and 8 Got: %+v", edits)
+		}
+		if got := applyEdits(old, edits); got != "one\r\ntwo\r\nfour\n" {
+			t.Errorf("Test failed. Expected: %q Got: %q", "one\r\ntwo\r\nfour\n", got)
+		}
+	})
+}
+
+func TestIgnoreCase(t *testing.T) {
+	// Test that texts differing only in case compare equal
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		language string
+	}{
+		{"ASCII", "Hello World", "hello WORLD", ""},
+		{"German sharp s", "Straße", "STRASSE", "de"},
+		{"Capital sharp s", "GROẞ", "groß", "de"},
+		{"Turkish dotted capital I", "İstanbul", "istanbul", "tr"},
+		{"Turkish dotless i", "DİYARBAKIR", "diyarbakır", "tr-TR"},
+		{"Greek final sigma", "ΟΔΟΣ", "οδος", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := DiffWithOptions(tt.oldText, tt.updated, DiffOptions{WindowSize: 2, IgnoreCase: true, Language: tt.language})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if len(edits) != 0 {
+				t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+			}
+		})
+	}
+
+	// Test that the Turkish rules are only used for Turkish
+	t.Run("Dotless i outside Turkish", func(t *testing.T) {
+		edits, err := DiffWithOptions("ISPARTA", "ısparta", DiffOptions{WindowSize: 2, IgnoreCase: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 0, NewStart: 0, Old: "I", New: "ı", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that edits keep the original content and positions
+	t.Run("Original content", func(t *testing.T) {
+		edits, err := DiffWithOptions("Die Straße ist LANG", "die STRASSE ist kurz", DiffOptions{WindowSize: 2, IgnoreCase: true, Language: "de"})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 15, NewStart: 16, Old: "LANG", New: "kurz", OldLen: 4, NewLen: 4}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that case still matters by default
+	t.Run("Case sensitive by default", func(t *testing.T) {
+		edits, err := DiffWithOptions("Straße", "STRASSE", DiffOptions{WindowSize: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 {
+			t.Errorf("Test failed. Expected: edits Got: none")
+		}
+	})
+
+	// Test that an edit covering part of the folded "ss" of "ß" replaces the whole character
+	t.Run("Edit inside an expanded character", func(t *testing.T) {
+		for _, tt := range [][2]string{{"aßb", "asb"}, {"Straße", "STRAS"}} {
+			edits, err := DiffWithOptions(tt[0], tt[1], DiffOptions{WindowSize: 1, IgnoreCase: true})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			got := applyEdits(tt[0], edits)
+			if !strings.EqualFold(got, tt[1]) {
+				t.Errorf("Test failed. Expected: %s Got: %s %+v", tt[1], got, edits)
+			}
+		}
+	})
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	timestamp := regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+
+	// Test that log lines differing only in their timestamp report no difference
+	t.Run("Masked timestamp", func(t *testing.T) {
+		oldLine := "2024-01-15T10:32:07Z INFO server started on port 8080"
+		updatedLine := "2025-11-03T23:01:59.125+02:00 INFO server started on port 8080"
+		edits, err := DiffWithOptions(oldLine, updatedLine, DiffOptions{WindowSize: 2, IgnorePatterns: []*regexp.Regexp{timestamp}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that differences outside the masked regions keep the original text and positions
+	t.Run("Difference outside the mask", func(t *testing.T) {
+		oldLine := "2024-01-15T10:32:07Z INFO port 8080"
+		updatedLine := "2024-01-15T11:00:00Z WARN port 8080"
+		edits, err := DiffWithOptions(oldLine, updatedLine, DiffOptions{WindowSize: 2, IgnorePatterns: []*regexp.Regexp{timestamp}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{newEdit(21, "INFO", "WARN")}
+		expected[0].NewStart = 21
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that an edit touching a masked region covers all of it, and several patterns apply
+	t.Run("Edit touching the mask", func(t *testing.T) {
+		id := regexp.MustCompile(`id=\w+`)
+		oldLine := "2024-01-15T10:32:07Z start id=ab12 done"
+		updatedLine := "2025-03-01T08:00:00Z start done"
+		edits, err := DiffWithOptions(oldLine, updatedLine, DiffOptions{WindowSize: 2, IgnorePatterns: []*regexp.Regexp{timestamp, id}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{newEdit(27, "id=ab12 ", "")}
+		expected[0].NewStart = 27
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
+
+func TestTabWidth(t *testing.T) {
+	tabCode := "func main() {\n\tif ok {\n\t\treturn\n\t}\n}\n"
+	spaceCode := "func main() {\n    if ok {\n        return\n    }\n}\n"
+
+	// Test that tab and four space indentation of the same code compare equal
+	t.Run("Tabs equal spaces", func(t *testing.T) {
+		edits, err := DiffWithOptions(tabCode, spaceCode, DiffOptions{WindowSize: 3, TabWidth: 4})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that indentation differs without the option or with another width
+	t.Run("Other widths differ", func(t *testing.T) {
+		for _, tabWidth := range []int{0, 2, 8} {
+			edits, err := DiffWithOptions(tabCode, spaceCode, DiffOptions{WindowSize: 3, TabWidth: tabWidth})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if len(edits) == 0 {
+				t.Errorf("Test failed. Tab width %d Expected: edits Got: none", tabWidth)
+			}
+		}
+	})
+
+	// Test that a tab reaches the next tab stop rather than adding a fixed number of spaces
+	t.Run("Tab stops", func(t *testing.T) {
+		edits, err := DiffWithOptions("ab\tc", "ab  c", DiffOptions{WindowSize: 1, TabWidth: 4})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that differences keep the original content and positions
+	t.Run("Original content", func(t *testing.T) {
+		edits, err := DiffWithOptions("\tx = 1\n", "    x = 2\n", DiffOptions{WindowSize: 1, TabWidth: 4})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{newEdit(5, "1", "2")}
+		expected[0].NewStart = 8
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that an edit inside the spaces of a tab covers the whole tab
+	t.Run("Edit inside a tab", func(t *testing.T) {
+		edits, err := DiffWithOptions("\tx", "  x", DiffOptions{WindowSize: 1, TabWidth: 4})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits("\tx", edits); got != "  x" || len(edits) != 1 || edits[0].Old != "\t" {
+			t.Errorf("Test failed. Expected: the tab replaced Got: %+v", edits)
+		}
+	})
+}
@@ -0,0 +1,471 @@
+package textcompare
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HashConfig selects the parameters of the polynomial rolling hash. A larger
+// prime reduces the chance of two different windows sharing a hash. Characters
+// and the base are reduced modulo Prime before being multiplied, so any prime
+// up to MaxPrime keeps the hash arithmetic within the int range.
+type HashConfig struct {
+	Prime int
+	// Base should exceed every character value hashed. Comparisons given no
+	// base pick ByteBase or RuneBase from the texts they compare.
+	Base int
+}
+
+const (
+	// MaxPrime is the largest prime accepted by HashConfig.
+	MaxPrime = math.MaxInt32
+	// ByteBase is the base for texts whose characters all fit in a byte.
+	ByteBase = 256
+	// RuneBase is the base for texts holding any Unicode code point.
+	RuneBase = utf8.MaxRune + 1
+)
+
+// DefaultHashConfig holds the hash parameters used when none are given.
+var DefaultHashConfig = HashConfig{Prime: 5381, Base: ByteBase}
+
+// alphabetBase returns the base suiting the characters of texts: ByteBase
+// when they all fit in a byte and RuneBase otherwise.
+func alphabetBase(texts ...string) int {
+	for _, text := range texts {
+		for _, r := range text {
+			if r >= ByteBase {
+				return RuneBase
+			}
+		}
+	}
+	return ByteBase
+}
+
+// withDefaults fills unset fields with the values of DefaultHashConfig.
+func (c HashConfig) withDefaults() HashConfig {
+	if c.Prime == 0 {
+		c.Prime = DefaultHashConfig.Prime
+	}
+	if c.Base == 0 {
+		c.Base = DefaultHashConfig.Base
+	}
+	return c
+}
+
+func (c HashConfig) validate() error {
+	c = c.withDefaults()
+	if c.Prime < 2 || c.Prime > MaxPrime {
+		return &CustomError{message: fmt.Sprintf("hash prime must be between 2 and %d, got %d", MaxPrime, c.Prime)}
+	}
+	if c.Base < 1 {
+		return &CustomError{message: fmt.Sprintf("hash base must be positive, got %d", c.Base)}
+	}
+	return nil
+}
+
+// DefaultPunctuation is the set of characters ignored by the
+// IgnorePunctuation option unless another one is given.
+const DefaultPunctuation = ".,;:!?'\"()[]{}-"
+
+// WhitespaceMode selects how whitespace differences are treated.
+type WhitespaceMode int
+
+const (
+	// WhitespaceExact compares whitespace like any other character.
+	WhitespaceExact WhitespaceMode = iota
+	// WhitespaceIgnore ignores all whitespace.
+	WhitespaceIgnore
+	// WhitespaceCollapse treats every run of whitespace as a single space.
+	WhitespaceCollapse
+)
+
+// Algorithm selects how the differences between two texts are searched.
+type Algorithm int
+
+const (
+	// AlgorithmRollingHash searches differences with rolling hash windows.
+	AlgorithmRollingHash Algorithm = iota
+	// AlgorithmMinimal finds a shortest edit script, see DiffMinimal.
+	AlgorithmMinimal
+)
+
+// Postprocessor rewrites the edits found between old and updated, such as
+// DetectMoves turning deletions and additions of the same content into moves.
+// The edits it returns must still apply to old.
+type Postprocessor func(edits []Edit, old, updated string) []Edit
+
+// DiffOptions configures a comparison made with DiffWithOptions.
+type DiffOptions struct {
+	// WindowSize is the number of characters hashed at once.
+	WindowSize int
+	// Hash selects the rolling hash parameters. Zero fields use
+	// DefaultHashConfig.
+	Hash HashConfig
+	// Whitespace selects how whitespace differences are treated. Edits are
+	// still reported with the original content and positions.
+	Whitespace WhitespaceMode
+	// NormalizeNewlines compares "\r\n" line endings as "\n". Edits are still
+	// reported with the original content and positions.
+	NormalizeNewlines bool
+	// TabWidth expands every tab to the spaces reaching the next tab stop,
+	// with a stop every TabWidth columns, so tab and space indentation of the
+	// same code compare equal. Zero keeps tabs. Edits are still reported with
+	// the original content and positions.
+	TabWidth int
+	// IgnorePunctuation ignores the characters in Punctuation. Edits are
+	// still reported with the original content and positions.
+	IgnorePunctuation bool
+	// Punctuation is the set of characters ignored by IgnorePunctuation.
+	// Empty means DefaultPunctuation.
+	Punctuation string
+	// IgnoreCase compares the case folded texts, so "Straße" equals
+	// "STRASSE". Edits are still reported with the original content and
+	// positions.
+	IgnoreCase bool
+	// Language is the BCP 47 tag of the language whose case rules
+	// IgnoreCase follows, such as "tr" where "I" folds to dotless "ı".
+	// Empty means the default Unicode rules.
+	Language string
+	// IgnorePatterns masks every match of these regular expressions in both
+	// texts before comparing them, so volatile content such as timestamps is
+	// never reported as a difference. Edits are still reported with the
+	// original content and positions, and an edit touching a masked region
+	// covers the whole of it.
+	IgnorePatterns []*regexp.Regexp
+	// Algorithm selects the search. The window size and hash parameters
+	// only apply to AlgorithmRollingHash.
+	Algorithm Algorithm
+	// MaxEdits stops the comparison once that many edits are found. Zero
+	// means no limit. DiffLimited reports whether the limit was reached.
+	MaxEdits int
+	// MinMatch merges edits separated by fewer than MinMatch unchanged
+	// characters into a single edit covering the characters between them,
+	// so a change is not split by accidental short matches. Zero means edits
+	// are never merged.
+	MinMatch int
+	// MaxContentLen shortens the Old and New content of every edit to that
+	// many runes followed by an ellipsis, while OldLen and NewLen keep the
+	// real lengths. Zero means no limit. Shortened edits no longer apply to
+	// the old text, so the option is only meant for displaying them.
+	MaxContentLen int
+	// AdaptiveWindow halves the window size every time the search finds a
+	// difference right where the previous one ended, so densely modified
+	// texts are aligned with smaller windows.
+	AdaptiveWindow bool
+	// UniqueAnchors first matches the windows whose content occurs only once
+	// in each text, and compares the regions between them independently, so
+	// repetitive texts align on their distinctive parts. It only applies to
+	// AlgorithmRollingHash.
+	UniqueAnchors bool
+	// SubEdits attaches the character level differences of every Modified
+	// edit to it, as AddSubEdits does, once the Postprocessors have run.
+	SubEdits bool
+	// Postprocessors rewrite the edits one after the other, in order, once
+	// the texts are compared and before MaxContentLen shortens the content.
+	Postprocessors []Postprocessor
+	// StrictHash makes the comparison fail when two different windows were
+	// found to share a hash, instead of only counting the collisions in
+	// DiffStats, to help choosing a prime for the texts compared.
+	StrictHash bool
+	// Logger receives a warning when the window size does not fit in one of
+	// the texts and is clamped to the shortest one. Nil means no warnings.
+	Logger *log.Logger
+}
+
+// differ holds the settings shared by every step of a comparison, and the
+// state of the comparison in progress.
+type differ struct {
+	hash       HashConfig
+	alphabet   bool // the hash base is picked from the texts compared
+	algorithm  Algorithm
+	whitespace WhitespaceMode
+	newlines   bool   // "\r\n" is compared as "\n"
+	tabWidth   int    // columns between tab stops when expanding tabs, 0 to keep them
+	ignored    string // characters removed before comparing
+	foldCase   bool
+	masks      []*regexp.Regexp    // patterns replaced by maskRune before comparing
+	caseRules  unicode.SpecialCase // language specific case mappings
+	maxEdits   int
+	strict     bool // hash collisions fail the comparison
+	minMatch   int  // unchanged runs shorter than this are absorbed by the edits
+	contentLen int  // runes of content kept on every edit, 0 for all
+	countOnly  bool // edits carry their lengths but no content
+	bytewise   bool // texts are compared byte by byte, each byte one character
+	adaptive   bool // the window shrinks while differences are dense
+	anchored   bool // unique windows are matched before searching
+	postprocs  []Postprocessor
+	subEdits   bool
+	logger     *log.Logger
+	ctx        context.Context  // cancels the comparison, nil when it cannot be
+	emit       func(Edit) error // receives the edits as they are found
+	emitOffset int              // NewStart minus Start after the edits emitted
+	searches   [2]TextSearch    // reused by every search, one per text compared
+	stats      DiffStats
+	found      int  // edits found so far
+	truncated  bool // MaxEdits was reached and the search stopped
+}
+
+func newDiffer(opts DiffOptions) *differ {
+	d := &differ{hash: opts.Hash.withDefaults(), alphabet: opts.Hash.Base == 0, algorithm: opts.Algorithm, whitespace: opts.Whitespace,
+		newlines: opts.NormalizeNewlines, tabWidth: opts.TabWidth, foldCase: opts.IgnoreCase, caseRules: caseMapping(opts.Language), maxEdits: opts.MaxEdits, minMatch: opts.MinMatch, contentLen: opts.MaxContentLen,
+		strict: opts.StrictHash, adaptive: opts.AdaptiveWindow, anchored: opts.UniqueAnchors, postprocs: opts.Postprocessors, subEdits: opts.SubEdits, masks: opts.IgnorePatterns, logger: opts.Logger}
+	if opts.IgnorePunctuation {
+		d.ignored = opts.Punctuation
+		if d.ignored == "" {
+			d.ignored = DefaultPunctuation
+		}
+	}
+	return d
+}
+
+// reset clears the state left by the previous comparison, keeping the
+// buffers of the searches for the next one.
+func (d *differ) reset() {
+	d.stats, d.found, d.truncated, d.emitOffset = DiffStats{}, 0, false, 0
+}
+
+// startSearch prepares ts to search text with the comparison settings, with
+// its window at the start of the text and its hash operations counted.
+func (d *differ) startSearch(ts *TextSearch, text string, windowSize int) {
+	if d.bytewise {
+		ts.createBytes(text, windowSize, d.hash)
+	} else {
+		ts.CreateBufferWithConfig(text, windowSize, d.hash)
+	}
+	ts.stats = &d.stats
+	ts.SetStart(0, windowSize)
+}
+
+// warnClamp logs a warning when windowSize is clamped to compare old and
+// updated.
+func (d *differ) warnClamp(old, updated string, windowSize int) {
+	if d.logger == nil {
+		return
+	}
+	len1, len2 := d.length(old), d.length(updated)
+	if window := clampWindow(len1, len2, windowSize); len1 > 0 && len2 > 0 && window != windowSize {
+		d.logger.Printf("window size %d exceeds the texts (%d and %d characters), clamped to %d", windowSize, len1, len2, window)
+	}
+}
+
+// length returns the number of characters of text: runes, or bytes when
+// comparing byte by byte.
+func (d *differ) length(text string) int {
+	if d.bytewise {
+		return len(text)
+	}
+	return utf8.RuneCountInString(text)
+}
+
+// prefix returns the first n characters of text.
+func (d *differ) prefix(text string, n int) string {
+	if d.bytewise {
+		return text[:n]
+	}
+	return string([]rune(text)[:n])
+}
+
+// rest returns text without its first n characters.
+func (d *differ) rest(text string, n int) string {
+	if d.bytewise {
+		return text[n:]
+	}
+	return string([]rune(text)[n:])
+}
+
+// first returns the first character of text, which must not be empty.
+func (d *differ) first(text string) rune {
+	if d.bytewise {
+		return rune(text[0])
+	}
+	r, _ := utf8.DecodeRuneInString(text)
+	return r
+}
+
+// content returns the first n characters of text as the content of an edit,
+// or nothing when only the lengths of the edits are wanted.
+func (d *differ) content(text string, n int) string {
+	if d.countOnly {
+		return ""
+	}
+	return d.prefix(text, n)
+}
+
+// edit builds the edit replacing previous with next at start.
+func (d *differ) edit(start int, previous, next string) Edit {
+	return d.editLen(start, previous, next, d.length(previous), d.length(next))
+}
+
+// editLen builds the edit replacing previous with next at start, given their
+// lengths in characters, keeping only the lengths when content is not wanted.
+func (d *differ) editLen(start int, previous, next string, oldLen, newLen int) Edit {
+	if d.countOnly {
+		return Edit{Op: opFor(oldLen, newLen), Start: start, OldLen: oldLen, NewLen: newLen}
+	}
+	return newEditLen(start, previous, next, oldLen, newLen)
+}
+
+// flush hands edits to the emit callback, when there is one, and returns
+// the emptied slice to collect the next edits in. The NewStart of every edit
+// is set from the edits emitted before it.
+func (d *differ) flush(edits []Edit) ([]Edit, error) {
+	if d.emit == nil {
+		return edits, nil
+	}
+	for _, edit := range edits {
+		edit.NewStart = edit.Start + d.emitOffset
+		d.emitOffset += edit.NewLen - edit.OldLen
+		if err := d.emit(edit); err != nil {
+			return nil, err
+		}
+	}
+	return edits[:0], nil
+}
+
+// checkInterval is the number of window moves between two checks of the
+// context of a comparison.
+const checkInterval = 1024
+
+// cancelled returns the error of the context of the comparison once it is
+// cancelled or past its deadline. Searches call it at every step, and the
+// context is only checked every checkInterval steps.
+func (d *differ) cancelled(step int) error {
+	if d.ctx == nil || step%checkInterval != 0 {
+		return nil
+	}
+	return d.ctx.Err()
+}
+
+// collided returns an error once a hash collision was found by a strict
+// comparison.
+func (d *differ) collided() error {
+	if !d.strict || d.stats.Collisions == 0 {
+		return nil
+	}
+	return &CustomError{message: fmt.Sprintf("hash collision between different windows with prime %d", d.hash.Prime)}
+}
+
+// limitReached reports whether the search must stop because MaxEdits edits
+// were already found, recording that the result is truncated.
+func (d *differ) limitReached() bool {
+	if d.maxEdits > 0 && d.found >= d.maxEdits {
+		d.truncated = true
+	}
+	return d.truncated
+}
+
+// normalizes reports whether texts are rewritten before being compared.
+func (d *differ) normalizes() bool {
+	return d.whitespace != WhitespaceExact || d.newlines || d.tabWidth > 0 || d.ignored != "" || d.foldCase || len(d.masks) > 0
+}
+
+// normalize rewrites a text according to the comparison settings.
+func (d *differ) normalize(text string) normalizedText {
+	nt := newNormalizedText(text)
+	for _, pattern := range d.masks {
+		nt = nt.mask(pattern)
+	}
+	if d.newlines {
+		nt = nt.normalizeNewlines()
+	}
+	if d.tabWidth > 0 {
+		nt = nt.expandTabs(d.tabWidth)
+	}
+	if d.foldCase {
+		nt = nt.foldCase(d.caseRules)
+	}
+	if d.ignored != "" {
+		nt = nt.removeRunes(d.ignored)
+	}
+	switch d.whitespace {
+	case WhitespaceIgnore:
+		nt = nt.removeWhitespace()
+	case WhitespaceCollapse:
+		nt = nt.collapseWhitespace()
+	}
+	return nt
+}
+
+// search finds the edits between two texts with the selected algorithm.
+func (d *differ) search(old, updated string, windowSize int) ([]Edit, error) {
+	if d.algorithm == AlgorithmMinimal {
+		return DiffMinimal(old, updated), nil
+	}
+	if d.anchored {
+		return d.anchoredEdits(old, updated, windowSize)
+	}
+	return d.collectEdits(old, updated, windowSize, 0)
+}
+
+// diff compares two texts and reports the edits against the original texts,
+// merged across short matches, rewritten by the postprocessors and with their
+// content shortened when the settings ask for it.
+func (d *differ) diff(old, updated string, windowSize int) ([]Edit, error) {
+	edits, err := d.compare(old, updated, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	if d.minMatch > 0 {
+		edits = absorbShortMatches(old, edits, d.minMatch)
+	}
+	for _, postprocess := range d.postprocs {
+		edits = postprocess(edits, old, updated)
+	}
+	if d.subEdits {
+		edits = AddSubEdits(edits)
+	}
+	return d.shorten(edits), nil
+}
+
+// compare searches the differences between old and updated, normalizing
+// both texts first when the settings ask for it.
+func (d *differ) compare(old, updated string, windowSize int) ([]Edit, error) {
+	if !d.normalizes() {
+		edits, err := d.search(old, updated, windowSize)
+		if err == nil {
+			err = d.collided()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return edits, nil
+	}
+	normalizedOld, normalizedUpdated := d.normalize(old), d.normalize(updated)
+	edits, err := d.search(string(normalizedOld.runes), string(normalizedUpdated.runes), windowSize)
+	if err == nil {
+		err = d.collided()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return mapEdits(edits, normalizedOld, normalizedUpdated), nil
+}
+
+// ellipsis marks content shortened by MaxContentLen.
+const ellipsis = "…"
+
+// shorten cuts the content of every edit down to MaxContentLen runes.
+func (d *differ) shorten(edits []Edit) []Edit {
+	if d.contentLen <= 0 {
+		return edits
+	}
+	for i := range edits {
+		edits[i].Old = shortenContent(edits[i].Old, d.contentLen)
+		edits[i].New = shortenContent(edits[i].New, d.contentLen)
+		edits[i].SubEdits = d.shorten(edits[i].SubEdits)
+	}
+	return edits
+}
+
+func shortenContent(content string, maxLen int) string {
+	runes := []rune(content)
+	if len(runes) <= maxLen {
+		return content
+	}
+	return string(runes[:maxLen]) + ellipsis
+}
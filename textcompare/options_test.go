@@ -0,0 +1,280 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHashConfig(t *testing.T) {
+	// Test that the prime and base do not change the result of a comparison
+	tests := []struct {
+		name    string
+		oldText string
+		updated string
+	}{
+		{"Added content", "hello", "hello world"},
+		{"Modified content", "hello world", "hello xorld"},
+		{"Mixed content", "the quick brown fox", "the quack brown cat jumps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			small, err := DiffWithOptions(tt.oldText, tt.updated, DiffOptions{WindowSize: 3, Hash: HashConfig{Prime: 5381}})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			large, err := DiffWithOptions(tt.oldText, tt.updated, DiffOptions{WindowSize: 3, Hash: HashConfig{Prime: 1000000007, Base: 131}})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(small, large) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", small, large)
+			}
+		})
+	}
+
+	// Test that unset fields fall back to the defaults
+	t.Run("Defaults", func(t *testing.T) {
+		var ts TextSearch
+		ts.CreateBufferWithConfig("hello", 2, HashConfig{})
+		if ts.prime != DefaultHashConfig.Prime || ts.base != DefaultHashConfig.Base {
+			t.Errorf("Test failed. Expected: %+v Got: prime %d base %d", DefaultHashConfig, ts.prime, ts.base)
+		}
+	})
+
+	// Test that invalid parameters are rejected
+	for _, cfg := range []HashConfig{{Prime: 1}, {Prime: -7}, {Base: -1}, {Prime: MaxPrime + 1}} {
+		if _, err := DiffWithOptions("hello", "jello", DiffOptions{WindowSize: 2, Hash: cfg}); err == nil {
+			t.Errorf("Test failed. Config %+v Expected an error", cfg)
+		}
+	}
+}
+
+func TestStrictHash(t *testing.T) {
+	// 'a' and 'c' are both odd, so their windows share a hash modulo 2
+	collide := HashConfig{Prime: 2, Base: ByteBase}
+
+	// Test that a collision is counted and does not change the edits
+	t.Run("Counted", func(t *testing.T) {
+		edits, stats, err := DiffWithStats("ab", "cb", DiffOptions{WindowSize: 1, Hash: collide})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if stats.Collisions == 0 {
+			t.Errorf("Test failed. Expected: collisions Got: %+v", stats)
+		}
+		expected, _ := DiffWithOptions("ab", "cb", DiffOptions{WindowSize: 1})
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that a strict comparison fails on the collision
+	t.Run("Strict", func(t *testing.T) {
+		edits, err := DiffWithOptions("ab", "cb", DiffOptions{WindowSize: 1, Hash: collide, StrictHash: true})
+		if err == nil || edits != nil {
+			t.Errorf("Test failed. Expected: an error Got: %+v %v", edits, err)
+		}
+	})
+
+	// Test that a strict comparison without collisions succeeds
+	t.Run("No collision", func(t *testing.T) {
+		_, stats, err := DiffWithStats("ab", "cb", DiffOptions{WindowSize: 1, StrictHash: true})
+		if err != nil || stats.Collisions != 0 {
+			t.Errorf("Test failed. Expected: no collisions Got: %+v %v", stats, err)
+		}
+	})
+}
+
+func TestMaxEdits(t *testing.T) {
+	// Test that texts with many differences stop after the given number of edits
+	t.Run("Many differences", func(t *testing.T) {
+		edits, truncated, err := DiffLimited("abababab", "axaxaxax", DiffOptions{WindowSize: 1, MaxEdits: 1})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if !truncated || len(edits) > 1 {
+			t.Errorf("Test failed. Expected: at most 1 edit, truncated Got: %+v, %t", edits, truncated)
+		}
+	})
+
+	// Test that a result within the limit is complete
+	t.Run("Within the limit", func(t *testing.T) {
+		edits, truncated, err := DiffLimited("hello world", "hello xorld", DiffOptions{WindowSize: 2, MaxEdits: 5})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if truncated || applyEdits("hello world", edits) != "hello xorld" {
+			t.Errorf("Test failed. Expected: a complete result Got: %+v, %t", edits, truncated)
+		}
+	})
+
+	// Test that zero means no limit
+	t.Run("No limit", func(t *testing.T) {
+		edits, truncated, err := DiffLimited("abcabcabcabc", "xyzxyzxyzxyzxyz", DiffOptions{WindowSize: 1})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if truncated || applyEdits("abcabcabcabc", edits) != "xyzxyzxyzxyzxyz" {
+			t.Errorf("Test failed. Expected: a complete result Got: %+v, %t", edits, truncated)
+		}
+	})
+}
+
+func TestAdaptiveWindow(t *testing.T) {
+	oldText := "abcdefghijklmnopqrstuvwxyz"
+	updatedText := "abXdefXhijXlmnXpqrXtuvXxyz"
+
+	// Test that a densely modified text is split into its small edits instead of one replacement
+	t.Run("Dense modifications", func(t *testing.T) {
+		fixed, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 4})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		adaptive, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 4, AdaptiveWindow: true})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(fixed) != 1 || len(adaptive) != 6 {
+			t.Errorf("Test failed. Expected: 1 and 6 edits Got: %d and %d", len(fixed), len(adaptive))
+		}
+		for _, edit := range adaptive {
+			if edit.OldLen != 1 || edit.NewLen != 1 {
+				t.Errorf("Test failed. Expected: single character edits Got: %+v", edit)
+			}
+		}
+		if got := applyEdits(oldText, adaptive); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	})
+
+	// Test that texts the fixed window already aligns give the same edits
+	t.Run("Sparse modifications", func(t *testing.T) {
+		fixed, _ := DiffWithOptions("hello world", "hello there", DiffOptions{WindowSize: 2})
+		adaptive, _ := DiffWithOptions("hello world", "hello there", DiffOptions{WindowSize: 2, AdaptiveWindow: true})
+		if !reflect.DeepEqual(fixed, adaptive) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", fixed, adaptive)
+		}
+	})
+}
+
+func TestMinMatch(t *testing.T) {
+	oldText, updatedText := "I like cats a lot", "I love dogs a lot"
+
+	// Test that a two character accidental match no longer splits the modification
+	t.Run("Short match absorbed", func(t *testing.T) {
+		plain, _ := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2})
+		if len(plain) != 2 {
+			t.Fatalf("Test failed. Expected: 2 edits split by \"e \" Got: %+v", plain)
+		}
+		edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, MinMatch: 3})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{newEdit(3, "ike cat", "ove dog")}
+		expected[0].NewStart = 3
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that matches as long as the threshold still split the edits
+	t.Run("Long match kept", func(t *testing.T) {
+		edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, MinMatch: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 2 {
+			t.Errorf("Test failed. Expected: 2 edits Got: %+v", edits)
+		}
+		if got := applyEdits(oldText, edits); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	})
+}
+
+func TestPostprocessors(t *testing.T) {
+	oldText, updatedText := "the cat sat", "the dog sat on it"
+
+	// Test that the postprocessors run in order, each receiving the edits of the previous one
+	t.Run("Run in order", func(t *testing.T) {
+		var calls []string
+		first := func(edits []Edit, old, updated string) []Edit {
+			calls = append(calls, "first")
+			if old != oldText || updated != updatedText {
+				t.Errorf("Test failed. Expected: %q %q Got: %q %q", oldText, updatedText, old, updated)
+			}
+			return edits[:1]
+		}
+		second := func(edits []Edit, old, updated string) []Edit {
+			calls = append(calls, "second")
+			if len(edits) != 1 {
+				t.Errorf("Test failed. Expected: 1 edit left by the first postprocessor Got: %+v", edits)
+			}
+			return append(edits, newEdit(len(old), "", "!"))
+		}
+		edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, Postprocessors: []Postprocessor{first, second}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if strings.Join(calls, " ") != "first second" {
+			t.Errorf("Test failed. Expected: first second Got: %s", strings.Join(calls, " "))
+		}
+		if len(edits) != 2 || edits[1].New != "!" {
+			t.Errorf("Test failed. Expected: the first edit and the appended one Got: %+v", edits)
+		}
+	})
+
+	// Test that an existing pass over edits plugs into the pipeline
+	t.Run("Existing pass", func(t *testing.T) {
+		collapse := func(edits []Edit, old, updated string) []Edit { return CollapseReplacements(edits) }
+		edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, Postprocessors: []Postprocessor{collapse}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits(oldText, edits); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	})
+}
+
+func TestMaxContentLen(t *testing.T) {
+	oldText := "intro " + strings.Repeat("a", 40) + " outro"
+	updatedText := "intro " + strings.Repeat("b", 30) + " outro"
+
+	// Test that long content is shortened and the real lengths are kept
+	t.Run("Long content is shortened", func(t *testing.T) {
+		edits, err := DiffWithOptions(oldText, updatedText, DiffOptions{WindowSize: 2, MaxContentLen: 5})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		full, _ := DiffEdits(oldText, updatedText, 2)
+		if len(edits) != len(full) {
+			t.Fatalf("Test failed. Expected: %d edits Got: %+v", len(full), edits)
+		}
+		for i, edit := range edits {
+			if edit.OldLen != full[i].OldLen || edit.NewLen != full[i].NewLen {
+				t.Errorf("Test failed. Expected: %d/%d Got: %d/%d", full[i].OldLen, full[i].NewLen, edit.OldLen, edit.NewLen)
+			}
+			if utf8.RuneCountInString(edit.Old) > 5+1 || utf8.RuneCountInString(edit.New) > 5+1 {
+				t.Errorf("Test failed. Expected: at most 5 runes and an ellipsis Got: %+v", edit)
+			}
+		}
+		if edits[0].Old != "aaaaa…" || edits[0].New != "bbbbb…" {
+			t.Errorf("Test failed. Expected: aaaaa… and bbbbb… Got: %s and %s", edits[0].Old, edits[0].New)
+		}
+	})
+
+	// Test that short content is left alone
+	t.Run("Short content is kept", func(t *testing.T) {
+		edits, err := DiffWithOptions("hello world", "hello xorld", DiffOptions{WindowSize: 2, MaxContentLen: 5})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 6, NewStart: 6, Old: "w", New: "x", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
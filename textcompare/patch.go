@@ -0,0 +1,119 @@
+package textcompare
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Patch is a list of edits that turns one text into another, in the same
+// order and with the same 0-based positions as the edits returned by
+// DiffEdits.
+type Patch []Edit
+
+// String renders the patch in the textual delta format produced by Diff.
+func (p Patch) String() string {
+	return formatEdits(p)
+}
+
+// Apply applies the patch to old. It fails when the edits are out of order,
+// fall outside old, or the content they replace does not match old.
+func (p Patch) Apply(old string) (string, error) {
+	oldRunes := []rune(old)
+	pos := 0
+	for _, edit := range expandMoves(p) {
+		end := edit.Start + utf8.RuneCountInString(edit.Old)
+		if edit.Start < pos || end > len(oldRunes) {
+			return "", &CustomError{message: fmt.Sprintf("edit at %d out of range", edit.Start)}
+		}
+		if string(oldRunes[edit.Start:end]) != edit.Old {
+			return "", &CustomError{message: fmt.Sprintf("edit at %d does not match the text: expected %q, found %q", edit.Start, edit.Old, string(oldRunes[edit.Start:end]))}
+		}
+		pos = end
+	}
+	return applyEdits(old, p), nil
+}
+
+// ApplyEdits applies edits returned by DiffEdits, or any comparison of the
+// package, directly to old without going through the textual delta format.
+// Every edit is positioned in old, so the shift caused by the edits before
+// it is accounted for. It fails like Patch.Apply on edits that do not fit old.
+func ApplyEdits(old string, edits []Edit) (string, error) {
+	return Patch(edits).Apply(old)
+}
+
+// AppliedEdit is an edit applied by ApplyEditsWithMap, with the part of the
+// produced text its new content occupies: Length runes starting at the
+// 0-based rune index Start. A deletion has a Length of 0 at the position the
+// removed content was.
+type AppliedEdit struct {
+	Edit   Edit `json:"edit"`
+	Start  int  `json:"start"`
+	Length int  `json:"length"`
+}
+
+// ApplyEditsWithMap applies edits to old like ApplyEdits and also reports
+// where every change landed in the produced text, so the changes can be
+// highlighted in it. A moved block is reported as the deletion and addition
+// it stands for.
+func ApplyEditsWithMap(old string, edits []Edit) (string, []AppliedEdit, error) {
+	updated, err := ApplyEdits(old, edits)
+	if err != nil {
+		return "", nil, err
+	}
+	applied := make([]AppliedEdit, 0, len(edits))
+	offset := 0
+	for _, edit := range expandMoves(edits) {
+		length := utf8.RuneCountInString(edit.New)
+		applied = append(applied, AppliedEdit{Edit: edit, Start: edit.Start + offset, Length: length})
+		offset += length - utf8.RuneCountInString(edit.Old)
+	}
+	return updated, applied, nil
+}
+
+// ParsePatch reads a patch back from the textual delta format produced by
+// Diff or Patch.String. The format only holds old positions, so NewStart is
+// derived from the edits that precede each one. The checksum line written by
+// DiffWithChecksum is skipped; ApplyPatch is the one checking it.
+func ParsePatch(s string) (Patch, error) {
+	var patch Patch
+	for i, line := range strings.Split(s, "\n") {
+		if len(line) == 0 || isChecksumLine(i, line) {
+			continue
+		}
+		edit, ok, err := parseDeltaLine(line)
+		if !ok {
+			return nil, &CustomError{message: fmt.Sprintf("line %d: missing %q", i+1, deltaPrefix)}
+		}
+		if err != nil {
+			return nil, &CustomError{message: fmt.Sprintf("line %d: %v", i+1, err)}
+		}
+		patch = append(patch, edit)
+	}
+	return Patch(setNewStarts(patch)), nil
+}
+
+// Verify compares old against updated and checks that applying the resulting
+// delta to old gives updated back. An error is returned when the comparison
+// fails or the delta does not apply to old.
+func Verify(old, updated string, windowSize int) (ok bool, err error) {
+	defer recoverPanic(&err)
+	delta, err := checkString(old, updated, windowSize, 0)
+	if err != nil {
+		return false, err
+	}
+	return verifyDelta(old, updated, delta)
+}
+
+// verifyDelta reports whether applying delta to old gives updated.
+func verifyDelta(old, updated, delta string) (bool, error) {
+	patch, err := ParsePatch(delta)
+	if err != nil {
+		return false, err
+	}
+	result, err := patch.Apply(old)
+	if err != nil {
+		return false, err
+	}
+	return result == updated, nil
+}
@@ -0,0 +1,216 @@
+package textcompare
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPatch(t *testing.T) {
+	// Test that a parsed patch applies like the delta it comes from
+	t.Run("Round trip", func(t *testing.T) {
+		tests := [][2]string{
+			{"hello world", "hello there"},
+			{"the cat sat", "a dog sat down"},
+			{"año nuevo", "año viejo"},
+			{"", "new text"},
+			{"old text", ""},
+		}
+		for _, tt := range tests {
+			edits, err := DiffEdits(tt[0], tt[1], 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			parsed, err := ParsePatch(Patch(edits).String())
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parsed, Patch(edits)) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", edits, parsed)
+			}
+			got, err := parsed.Apply(tt[0])
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if got != tt[1] {
+				t.Errorf("Test failed. Expected: %s Got: %s", tt[1], got)
+			}
+		}
+	})
+
+	// Test that the textual form matches Diff
+	t.Run("String matches Diff", func(t *testing.T) {
+		delta, _ := Diff("hello world", "hello there", 2)
+		edits, _ := DiffEdits("hello world", "hello there", 2)
+		if got := Patch(edits).String(); got != delta {
+			t.Errorf("Test failed. Expected: %q Got: %q", delta, got)
+		}
+	})
+
+	// Test that malformed patches are rejected
+	t.Run("Parse errors", func(t *testing.T) {
+		for _, s := range []string{"no marker here", "Start character: x [+++ a]"} {
+			if _, err := ParsePatch(s); err == nil {
+				t.Errorf("Test failed. Expected: an error for %q Got: nil", s)
+			}
+		}
+	})
+
+	// Test that a patch not matching the text is rejected
+	t.Run("Apply errors", func(t *testing.T) {
+		patches := []Patch{
+			{{Op: Deleted, Start: 0, Old: "xy", OldLen: 2}},
+			{{Op: Added, Start: 10, New: "z", NewLen: 1}},
+			{{Op: Deleted, Start: 2, Old: "c", OldLen: 1}, {Op: Deleted, Start: 0, Old: "a", OldLen: 1}},
+		}
+		for _, patch := range patches {
+			if _, err := patch.Apply("abc"); err == nil {
+				t.Errorf("Test failed. Expected: an error for %+v Got: nil", patch)
+			}
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	// Test that correct comparisons verify
+	tests := [][2]string{
+		{"hello world", "hello there"},
+		{"the cat sat", "a dog sat down"},
+		{"año nuevo", "año viejo"},
+		{"", "new text"},
+		{"same", "same"},
+	}
+	for _, tt := range tests {
+		for windowSize := 1; windowSize <= 4; windowSize++ {
+			ok, err := Verify(tt[0], tt[1], windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !ok {
+				t.Errorf("Test failed. %q Window %d Expected: true Got: false", tt, windowSize)
+			}
+		}
+	}
+
+	// Test that a delta missing an edit is caught
+	t.Run("Incomplete delta", func(t *testing.T) {
+		delta := "Start character: 7 [--- worl][+++ ther]\n"
+		ok, err := verifyDelta("hello world", "hello there", delta)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if ok {
+			t.Errorf("Test failed. Expected: false Got: true")
+		}
+	})
+
+	// Test that a delta not matching the old text is caught
+	t.Run("Mismatched delta", func(t *testing.T) {
+		ok, err := verifyDelta("hello world", "hello there", "Start character: 7 [--- xyzw][+++ ther]\n")
+		if ok || err == nil {
+			t.Errorf("Test failed. Expected: false and an error Got: %t, %v", ok, err)
+		}
+	})
+
+	// Test that comparison errors are reported
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, err := Verify("a", "b", 0); err == nil {
+			t.Errorf("Test failed. Expected: an error Got: nil")
+		}
+	})
+}
+
+func TestApplyEdits(t *testing.T) {
+	// Test that applying the edits of a comparison gives the updated text back
+	t.Run("Round trip", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		alphabet := []rune("ab cñ\n]")
+		randomText := func() string {
+			text := make([]rune, r.Intn(20))
+			for i := range text {
+				text[i] = alphabet[r.Intn(len(alphabet))]
+			}
+			return string(text)
+		}
+		for i := 0; i < 2000; i++ {
+			oldText, updatedText := randomText(), randomText()
+			windowSize := r.Intn(4) + 1
+			edits, err := DiffEdits(oldText, updatedText, windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			got, err := ApplyEdits(oldText, edits)
+			if err != nil || got != updatedText {
+				t.Fatalf("Test failed. Old: %q Window: %d Expected: %q Got: %q %v", oldText, windowSize, updatedText, got, err)
+			}
+		}
+	})
+
+	// Test that several edits compose, each positioned in the old text
+	t.Run("Several edits", func(t *testing.T) {
+		edits := []Edit{newEdit(0, "the", "a"), newEdit(4, "cat", "tiger"), newEdit(11, "", " down")}
+		got, err := ApplyEdits("the cat sat", edits)
+		if err != nil || got != "a tiger sat down" {
+			t.Errorf("Test failed. Expected: a tiger sat down Got: %s %v", got, err)
+		}
+	})
+
+	// Test that edits not fitting the old text are rejected
+	t.Run("Mismatched edits", func(t *testing.T) {
+		for _, edits := range [][]Edit{{newEdit(4, "dog", "cow")}, {newEdit(20, "", "x")}} {
+			if _, err := ApplyEdits("the cat sat", edits); err == nil {
+				t.Errorf("Test failed. Edits %+v Expected an error", edits)
+			}
+		}
+	})
+}
+
+func TestApplyEditsWithMap(t *testing.T) {
+	// Test that the reported positions index the new content in the produced text
+	t.Run("Positions in the result", func(t *testing.T) {
+		pairs := [][2]string{
+			{"the cat sat", "a tiger sat down"},
+			{"hello world", "jello wörld!"},
+			{"año nuevo", "el año viejo"},
+			{"remove me", ""},
+		}
+		for _, pair := range pairs {
+			edits, err := DiffEdits(pair[0], pair[1], 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			updated, applied, err := ApplyEditsWithMap(pair[0], edits)
+			if err != nil || updated != pair[1] {
+				t.Fatalf("Test failed. Expected: %s Got: %s %v", pair[1], updated, err)
+			}
+			if len(applied) != len(edits) {
+				t.Fatalf("Test failed. Expected: %d applied edits Got: %d", len(edits), len(applied))
+			}
+			runes := []rune(updated)
+			for _, a := range applied {
+				if got := string(runes[a.Start : a.Start+a.Length]); got != a.Edit.New {
+					t.Errorf("Test failed. %q Expected: %q Got: %q", pair, a.Edit.New, got)
+				}
+			}
+		}
+	})
+
+	// Test that a deletion is reported with no length where the content was removed
+	t.Run("Deletion", func(t *testing.T) {
+		_, applied, err := ApplyEditsWithMap("the big cat", []Edit{newEdit(0, "the ", ""), newEdit(4, "big ", "")})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []AppliedEdit{{Edit: newEdit(0, "the ", ""), Start: 0}, {Edit: newEdit(4, "big ", ""), Start: 0}}
+		if !reflect.DeepEqual(applied, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, applied)
+		}
+	})
+
+	// Test that edits not fitting the old text are rejected
+	t.Run("Mismatched edits", func(t *testing.T) {
+		if _, _, err := ApplyEditsWithMap("the cat", []Edit{newEdit(4, "dog", "cow")}); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
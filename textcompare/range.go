@@ -0,0 +1,37 @@
+package textcompare
+
+import "fmt"
+
+// runeRange returns the runes of text between start and end, failing when the
+// range does not lie within the text.
+func runeRange(text []rune, start, end int) (string, error) {
+	if start < 0 || end < start || end > len(text) {
+		return "", &CustomError{message: fmt.Sprintf("range %d-%d is outside the text (%d characters)", start, end, len(text))}
+	}
+	return string(text[start:end]), nil
+}
+
+// DiffRange compares only the runes [oldStart, oldEnd) of old against the
+// runes [newStart, newEnd) of updated. The edits are reported in the
+// coordinates of the whole texts, so Start is a rune index into old and
+// NewStart a rune index into updated.
+func DiffRange(old, updated string, oldStart, oldEnd, newStart, newEnd, windowSize int) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	oldRegion, err := runeRange([]rune(old), oldStart, oldEnd)
+	if err != nil {
+		return nil, err
+	}
+	updatedRegion, err := runeRange([]rune(updated), newStart, newEnd)
+	if err != nil {
+		return nil, err
+	}
+	edits, err = newDiffer(DiffOptions{}).collectEdits(oldRegion, updatedRegion, windowSize, oldStart)
+	if err != nil {
+		return nil, err
+	}
+	// NewStart was counted from oldStart like Start, move it to updated
+	for i := range edits {
+		edits[i].NewStart += newStart - oldStart
+	}
+	return edits, nil
+}
@@ -0,0 +1,61 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffRange(t *testing.T) {
+	// Test that positions are reported in the coordinates of the whole texts
+	t.Run("Offset positions", func(t *testing.T) {
+		oldText := "header\nthe quick brown fox\nfooter"
+		updatedText := "new header line\nthe quick brawn fox\nfooter"
+		edits, err := DiffRange(oldText, updatedText, 7, 26, 16, 35, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{{Op: Modified, Start: 19, NewStart: 28, Old: "o", New: "a", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that differences outside the range are ignored
+	t.Run("Changes outside the range", func(t *testing.T) {
+		edits, err := DiffRange("aaa middle bbb", "xxx middle yyy", 3, 11, 3, 11, 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that the edits rebuild the region of the updated text
+	t.Run("Region round trip", func(t *testing.T) {
+		oldText, updatedText := "añadido: uno dos tres", "añadido: uno tres cuatro"
+		edits, err := DiffRange(oldText, updatedText, 9, 21, 9, 24, 1)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits(oldText, edits); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+		for _, edit := range edits {
+			if edit.Start < 9 || edit.NewStart < 9 {
+				t.Errorf("Test failed. Expected: positions from 9 Got: %+v", edit)
+			}
+		}
+	})
+
+	// Test that ranges outside the texts are rejected
+	t.Run("Invalid ranges", func(t *testing.T) {
+		ranges := [][4]int{{-1, 2, 0, 2}, {3, 2, 0, 2}, {0, 9, 0, 2}, {0, 2, 0, 9}}
+		for _, r := range ranges {
+			_, err := DiffRange("hello", "hello", r[0], r[1], r[2], r[3], 1)
+			if _, ok := err.(*CustomError); !ok {
+				t.Errorf("Test failed. Range %v Expected a *CustomError Got: %v", r, err)
+			}
+		}
+	})
+}
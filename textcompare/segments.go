@@ -0,0 +1,81 @@
+package textcompare
+
+// SegmentKind identifies whether a Segment is unchanged or how it changed.
+type SegmentKind int
+
+const (
+	// SegmentEqual marks content shared by both texts.
+	SegmentEqual SegmentKind = iota
+	// SegmentAdded marks content present only in the updated text.
+	SegmentAdded
+	// SegmentDeleted marks content present only in the old text.
+	SegmentDeleted
+	// SegmentModified marks content of the old text replaced by new content.
+	SegmentModified
+)
+
+// String returns the lower case name of the kind.
+func (k SegmentKind) String() string {
+	switch k {
+	case SegmentEqual:
+		return "equal"
+	case SegmentAdded:
+		return "added"
+	case SegmentDeleted:
+		return "deleted"
+	case SegmentModified:
+		return "modified"
+	}
+	return "unknown"
+}
+
+// Segment is a run of a comparison, either unchanged or changed. Old is the
+// content it covers in the old text and New in the updated one, so both hold
+// the same content for an equal segment. Start and NewStart are the rune
+// indexes where it begins in the old and updated texts.
+type Segment struct {
+	Kind     SegmentKind
+	Start    int
+	NewStart int
+	Old      string
+	New      string
+}
+
+// segmentKinds maps the kind of an edit to the kind of its segment.
+var segmentKinds = map[OpKind]SegmentKind{Added: SegmentAdded, Deleted: SegmentDeleted, Modified: SegmentModified}
+
+// DiffSegments compares old against updated and returns the unchanged runs
+// interleaved with the edits, in order, so that together they cover both
+// texts: joining the Old of every segment gives old back, and joining their
+// New gives updated.
+func DiffSegments(old, updated string, windowSize int) (segments []Segment, err error) {
+	defer recoverPanic(&err)
+	edits, err := DiffEdits(old, updated, windowSize)
+	if err != nil {
+		return nil, err
+	}
+	return segmentsOf(old, edits), nil
+}
+
+// segmentsOf interleaves edits, sorted by Start and with NewStart set, with
+// the unchanged runs of old between them.
+func segmentsOf(old string, edits []Edit) []Segment {
+	oldRunes := []rune(old)
+	var segments []Segment
+	pos, newPos := 0, 0
+	equal := func(end int) {
+		if end > pos {
+			content := string(oldRunes[pos:end])
+			segments = append(segments, Segment{Kind: SegmentEqual, Start: pos, NewStart: newPos, Old: content, New: content})
+			newPos += end - pos
+			pos = end
+		}
+	}
+	for _, edit := range edits {
+		equal(edit.Start)
+		segments = append(segments, Segment{Kind: segmentKinds[edit.Op], Start: edit.Start, NewStart: edit.NewStart, Old: edit.Old, New: edit.New})
+		pos, newPos = edit.Start+edit.OldLen, edit.NewStart+edit.NewLen
+	}
+	equal(len(oldRunes))
+	return segments
+}
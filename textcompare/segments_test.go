@@ -0,0 +1,64 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffSegments(t *testing.T) {
+	// Test that the segments rebuild both texts
+	pairs := [][2]string{
+		{"the cat sat on the mat", "a dog sat down on a mat!"},
+		{"hello world", "hello world"},
+		{"", "new text"},
+		{"old text", ""},
+		{"año nuevo 👋", "año viejo 🌍!"},
+	}
+	for _, pair := range pairs {
+		t.Run(pair[0], func(t *testing.T) {
+			segments, err := DiffSegments(pair[0], pair[1], 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			var old, updated strings.Builder
+			for _, segment := range segments {
+				if segment.Kind == SegmentEqual || segment.Kind == SegmentDeleted || segment.Kind == SegmentModified {
+					old.WriteString(segment.Old)
+				}
+				if segment.Kind == SegmentEqual || segment.Kind == SegmentAdded || segment.Kind == SegmentModified {
+					updated.WriteString(segment.New)
+				}
+			}
+			if old.String() != pair[0] {
+				t.Errorf("Test failed. Expected: %s Got: %s", pair[0], old.String())
+			}
+			if updated.String() != pair[1] {
+				t.Errorf("Test failed. Expected: %s Got: %s", pair[1], updated.String())
+			}
+		})
+	}
+
+	// Test the kinds and positions of the segments
+	t.Run("Interleaved segments", func(t *testing.T) {
+		segments, err := DiffSegments("hello world", "hello there world", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Segment{
+			{Kind: SegmentEqual, Start: 0, NewStart: 0, Old: "hello ", New: "hello "},
+			{Kind: SegmentAdded, Start: 6, NewStart: 6, New: "there "},
+			{Kind: SegmentEqual, Start: 6, NewStart: 12, Old: "world", New: "world"},
+		}
+		if !reflect.DeepEqual(segments, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, segments)
+		}
+	})
+
+	// Test that invalid input is reported
+	t.Run("Invalid window", func(t *testing.T) {
+		if _, err := DiffSegments("hello", "jello", 0); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
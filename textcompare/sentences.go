@@ -0,0 +1,45 @@
+package textcompare
+
+import (
+	"strings"
+	"unicode"
+)
+
+// sentenceTerminators are the characters ending a sentence when whitespace
+// follows them.
+const sentenceTerminators = ".!?"
+
+// splitSentences splits text into sentences. A sentence ends after a run of
+// terminators followed by whitespace, and keeps both the terminators and the
+// whitespace, so that joining the sentences gives back the original text.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	terminated, spaced := false, false
+	for i, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			spaced = terminated
+		case spaced:
+			sentences = append(sentences, text[start:i])
+			start = i
+			terminated, spaced = strings.ContainsRune(sentenceTerminators, r), false
+		default:
+			terminated = strings.ContainsRune(sentenceTerminators, r)
+		}
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// DiffSentences compares two texts sentence by sentence, splitting them after
+// ".", "!" or "?" followed by whitespace. Every sentence, with its terminators
+// and the whitespace after them, is an atomic unit, so an edited sentence is
+// reported as a single modification and added or removed sentences as
+// additions and deletions. Edit positions are rune indexes into old at
+// sentence boundaries.
+func DiffSentences(old, updated string) []Edit {
+	return diffTokensAt(splitSentences(old), splitSentences(updated))
+}
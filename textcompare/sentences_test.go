@@ -0,0 +1,68 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Terminators", "Hi. How are you? Fine!", []string{"Hi. ", "How are you? ", "Fine!"}},
+		{"Repeated terminators", "Really?! Yes...  Ok", []string{"Really?! ", "Yes...  ", "Ok"}},
+		{"No whitespace after", "Version 1.2 is out.Now", []string{"Version 1.2 is out.Now"}},
+		{"Line breaks", "One.\nTwo.\n", []string{"One.\n", "Two.\n"}},
+		{"Empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitSentences(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Test failed. Expected: %q Got: %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDiffSentences(t *testing.T) {
+	paragraph := "The cat sat. It was happy! Then it left."
+	tests := []struct {
+		name     string
+		updated  string
+		expected []Edit
+	}{
+		{
+			"Edited sentence",
+			"The cat sat. It was very happy! Then it left.",
+			[]Edit{{Op: Modified, Start: 13, NewStart: 13, Old: "It was happy! ", New: "It was very happy! ", OldLen: 14, NewLen: 19}},
+		},
+		{
+			"Added sentence",
+			"The cat sat. It was happy! It purred. Then it left.",
+			[]Edit{{Op: Added, Start: 27, NewStart: 27, New: "It purred. ", NewLen: 11}},
+		},
+		{
+			"Removed sentence",
+			"The cat sat. Then it left.",
+			[]Edit{{Op: Deleted, Start: 13, NewStart: 13, Old: "It was happy! ", OldLen: 14}},
+		},
+		{
+			"Unchanged paragraph",
+			paragraph,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := DiffSentences(paragraph, tt.updated)
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+			if got := applyEdits(paragraph, edits); got != tt.updated {
+				t.Errorf("Test failed. Expected: %s Got: %s", tt.updated, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package textcompare
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// streamChunk is the minimum number of characters buffered from each reader
+// by DiffReaders.
+const streamChunk = 4096
+
+// runeStream reads characters from a reader on demand.
+type runeStream struct {
+	reader *bufio.Reader
+	eof    bool
+}
+
+func newRuneStream(r io.Reader) *runeStream {
+	return &runeStream{reader: bufio.NewReader(r)}
+}
+
+// fill appends characters to buf until it holds size of them or the reader
+// is exhausted.
+func (s *runeStream) fill(buf []rune, size int) ([]rune, error) {
+	for !s.eof && len(buf) < size {
+		r, _, err := s.reader.ReadRune()
+		if err == io.EOF {
+			s.eof = true
+			break
+		}
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, r)
+	}
+	return buf, nil
+}
+
+// DiffReaders compares the content of two readers without loading them in
+// memory. Only a chunk of max(4096, 64*windowSize) characters of each reader
+// is buffered at a time: the chunks are compared, the edits that end in the
+// first half of both chunks are kept, and the buffers slide past them. Memory
+// use is therefore bounded by the chunk size plus the returned edits, whatever
+// the size of the inputs. Edits spanning more than half a chunk are reported
+// in several pieces. Edit positions are 0-based rune indexes into old.
+func DiffReaders(old, updated io.Reader, windowSize int) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	if err := validateWindow(windowSize); err != nil {
+		return nil, err
+	}
+	chunk := max(streamChunk, 64*windowSize)
+	margin := chunk / 2
+	oldStream, newStream := newRuneStream(old), newRuneStream(updated)
+	var oldBuf, newBuf []rune
+	d := newDiffer(DiffOptions{})
+	oldBase, newBase := 0, 0
+	for {
+		var err error
+		if oldBuf, err = oldStream.fill(oldBuf, chunk); err != nil {
+			return nil, err
+		}
+		if newBuf, err = newStream.fill(newBuf, chunk); err != nil {
+			return nil, err
+		}
+
+		// Skip the shared content before running the window search
+		prefix := 0
+		for prefix < len(oldBuf) && prefix < len(newBuf) && oldBuf[prefix] == newBuf[prefix] {
+			prefix++
+		}
+		oldBuf = append(oldBuf[:0], oldBuf[prefix:]...)
+		newBuf = append(newBuf[:0], newBuf[prefix:]...)
+		oldBase += prefix
+		newBase += prefix
+
+		if oldStream.eof && newStream.eof {
+			rest, err := d.collectEdits(string(oldBuf), string(newBuf), windowSize, 0)
+			if err != nil {
+				return nil, err
+			}
+			for _, edit := range rest {
+				edit.Start += oldBase
+				edit.NewStart += newBase
+				edits = append(edits, edit)
+			}
+			return edits, nil
+		}
+		if prefix > 0 {
+			// Refill the buffers before comparing
+			continue
+		}
+
+		part, err := d.collectEdits(string(oldBuf), string(newBuf), windowSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the edits far enough from the end of the buffers to be
+		// unaffected by the content not read yet. The first one is always
+		// kept so that the comparison makes progress.
+		oldCut, newCut, offset := 0, 0, 0
+		for i, edit := range part {
+			oldEnd := edit.Start + utf8.RuneCountInString(edit.Old)
+			newEnd := edit.Start + offset + utf8.RuneCountInString(edit.New)
+			if i > 0 && (oldEnd > len(oldBuf)-margin || newEnd > len(newBuf)-margin) {
+				break
+			}
+			edit.Start += oldBase
+			edit.NewStart += newBase
+			edits = append(edits, edit)
+			offset = newEnd - oldEnd
+			oldCut, newCut = oldEnd, newEnd
+		}
+		oldBuf = append(oldBuf[:0], oldBuf[oldCut:]...)
+		newBuf = append(newBuf[:0], newBuf[newCut:]...)
+		oldBase += oldCut
+		newBase += newCut
+	}
+}
+
+// DiffStream compares old against updated like DiffEdits, but hands every
+// edit to emit as soon as it is found instead of collecting them, so callers
+// can process and discard the edits of large comparisons one by one. The
+// edits arrive in the order DiffEdits returns them. An error returned by emit
+// stops the comparison and is returned by DiffStream.
+func DiffStream(old, updated string, windowSize int, emit func(Edit) error) (err error) {
+	defer recoverPanic(&err)
+	d := newDiffer(DiffOptions{})
+	d.emit = emit
+	_, err = d.collectEdits(old, updated, windowSize, 0)
+	return err
+}
+
+// lineStream reads lines from a scanner on demand.
+type lineStream struct {
+	scanner *bufio.Scanner
+	eof     bool
+}
+
+// fill appends lines to buf until it holds size of them or the scanner is
+// exhausted.
+func (s *lineStream) fill(buf []string, size int) ([]string, error) {
+	for !s.eof && len(buf) < size {
+		if !s.scanner.Scan() {
+			s.eof = true
+			return buf, s.scanner.Err()
+		}
+		buf = append(buf, s.scanner.Text())
+	}
+	return buf, nil
+}
+
+// resync returns the number of lines of old and updated to skip so that both
+// continue on the same line, skipping as few lines as possible and fewer old
+// lines on a tie, and whether such a line is buffered.
+func resync(old, updated []string) (int, int, bool) {
+	first := make(map[string]int, len(updated))
+	for j := len(updated) - 1; j >= 0; j-- {
+		first[updated[j]] = j
+	}
+	best, bestOld, bestNew := -1, 0, 0
+	for i, line := range old {
+		if j, ok := first[line]; ok && (best < 0 || i+j < best) {
+			best, bestOld, bestNew = i+j, i, j
+		}
+	}
+	return bestOld, bestNew, best >= 0
+}
+
+// DiffLineStream compares two streams of lines as they are read, for instance
+// two growing log files, and hands every added, deleted or changed run of
+// lines to emit as soon as it is known. At most lookahead lines past the
+// current one are buffered from each scanner: after a difference the streams
+// resynchronize on the closest line found in both buffers, and when there is
+// none the current lines are reported as changed and the comparison moves on.
+// Lines left on one stream once the other ends are reported as added or
+// deleted. Edits are positioned and sized like those of DiffLines, in lines
+// counted from 0, with the content of the lines joined with "\n", and a long
+// change may arrive in several edits. An error of a scanner or of emit stops
+// the comparison and is returned.
+func DiffLineStream(old, updated *bufio.Scanner, lookahead int, emit func(Edit) error) (err error) {
+	defer recoverPanic(&err)
+	if lookahead <= 0 {
+		return &CustomError{message: fmt.Sprintf("lookahead must be positive, got %d", lookahead)}
+	}
+	oldStream, newStream := &lineStream{scanner: old}, &lineStream{scanner: updated}
+	var oldBuf, newBuf []string
+	oldBase, newBase := 0, 0
+	for {
+		var err error
+		if oldBuf, err = oldStream.fill(oldBuf, lookahead+1); err != nil {
+			return err
+		}
+		if newBuf, err = newStream.fill(newBuf, lookahead+1); err != nil {
+			return err
+		}
+		if len(oldBuf) == 0 && len(newBuf) == 0 {
+			return nil
+		}
+		if len(oldBuf) > 0 && len(newBuf) > 0 && oldBuf[0] == newBuf[0] {
+			oldBuf, newBuf = oldBuf[1:], newBuf[1:]
+			oldBase++
+			newBase++
+			continue
+		}
+
+		oldCut, newCut, found := resync(oldBuf, newBuf)
+		if !found {
+			// Everything buffered differs, which is the rest of the streams
+			// when both are read or one of them ended
+			oldCut, newCut = len(oldBuf), len(newBuf)
+			if len(oldBuf) > 0 && len(newBuf) > 0 && (!oldStream.eof || !newStream.eof) {
+				// No resynchronization within the lookahead: the current lines changed
+				oldCut, newCut = 1, 1
+			}
+		}
+		previous, next := strings.Join(oldBuf[:oldCut], "\n"), strings.Join(newBuf[:newCut], "\n")
+		edit := Edit{Op: opFor(oldCut, newCut), Start: oldBase, NewStart: newBase, Old: previous, New: next,
+			OldLen: oldCut, NewLen: newCut, WhitespaceOnly: whitespaceOnly(previous, next)}
+		if err := emit(edit); err != nil {
+			return err
+		}
+		oldBuf, newBuf = oldBuf[oldCut:], newBuf[newCut:]
+		oldBase += oldCut
+		newBase += newCut
+	}
+}
@@ -0,0 +1,220 @@
+package textcompare
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffReaders(t *testing.T) {
+	// Test that small inputs give the same result as DiffEdits
+	tests := []struct {
+		name    string
+		oldText string
+		updated string
+	}{
+		{"Added content", "hello", "hello world"},
+		{"Deleted content", "hello world", "hello"},
+		{"Modified content", "hello world", "hello xorld"},
+		{"Unicode content", "el niño comió", "el nino comió"},
+		{"No changes", "hello world", "hello world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, _ := DiffEdits(tt.oldText, tt.updated, 2)
+			got, err := DiffReaders(strings.NewReader(tt.oldText), strings.NewReader(tt.updated), 2)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+			}
+		})
+	}
+
+	// Test that inputs spanning many chunks are reconstructed from the edits
+	t.Run("Inputs larger than a chunk", func(t *testing.T) {
+		line := "lorem ipsum dolor sit amet, consectetur adipiscing elit\n"
+		oldText := strings.Repeat(line, 200)
+		updatedText := strings.Replace(oldText, "dolor", "color", 40)
+		updatedText = strings.Replace(updatedText, "elit\n", "elit, sed do\n", 7)
+		updatedText = updatedText[:len(updatedText)/2] + strings.Repeat("inserted ", 400) + updatedText[len(updatedText)/2:]
+		edits, err := DiffReaders(strings.NewReader(oldText), strings.NewReader(updatedText), 4)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits(oldText, edits); got != updatedText {
+			t.Errorf("Test failed. Reconstructed text differs from the updated text")
+		}
+	})
+
+	// Test that read errors are returned
+	t.Run("Read error", func(t *testing.T) {
+		if _, err := DiffReaders(failingReader{}, strings.NewReader("hello"), 2); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestDiffStream(t *testing.T) {
+	// Test that the edits received through the callback are those of DiffEdits
+	pairs := [][2]string{
+		{"hello world", "hello there"},
+		{"the cat sat on the mat", "a dog sat down on a mat!"},
+		{"año nuevo 👋", "año viejo 🌍!"},
+		{"", "new text"},
+		{"same", "same"},
+	}
+	for _, pair := range pairs {
+		t.Run(pair[0], func(t *testing.T) {
+			var got []Edit
+			err := DiffStream(pair[0], pair[1], 2, func(edit Edit) error {
+				got = append(got, edit)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			expected, _ := DiffEdits(pair[0], pair[1], 2)
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+			}
+		})
+	}
+
+	// Test that an error returned by the callback aborts the comparison
+	t.Run("Abort", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := DiffStream("abababab", "axaxaxax", 1, func(edit Edit) error {
+			calls++
+			return stop
+		})
+		if err != stop || calls != 1 {
+			t.Errorf("Test failed. Expected: stop after 1 call Got: %v after %d calls", err, calls)
+		}
+	})
+
+	// Test that invalid input is reported without calling back
+	t.Run("Invalid window", func(t *testing.T) {
+		err := DiffStream("hello", "jello", 0, func(edit Edit) error {
+			t.Errorf("Test failed. Unexpected edit: %+v", edit)
+			return nil
+		})
+		if err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
+
+func TestDiffLineStream(t *testing.T) {
+	scanners := func(old, updated string) (*bufio.Scanner, *bufio.Scanner) {
+		return bufio.NewScanner(strings.NewReader(old)), bufio.NewScanner(strings.NewReader(updated))
+	}
+	collect := func(old, updated string, lookahead int) ([]Edit, error) {
+		var edits []Edit
+		oldScanner, newScanner := scanners(old, updated)
+		err := DiffLineStream(oldScanner, newScanner, lookahead, func(edit Edit) error {
+			edits = append(edits, edit)
+			return nil
+		})
+		return edits, err
+	}
+	lineEdit := func(start, newStart int, previous, next string, oldLen, newLen int) Edit {
+		return Edit{Op: opFor(oldLen, newLen), Start: start, NewStart: newStart, Old: previous, New: next,
+			OldLen: oldLen, NewLen: newLen, WhitespaceOnly: whitespaceOnly(previous, next)}
+	}
+
+	// Test added, deleted and changed lines in two multi-line texts
+	tests := []struct {
+		name     string
+		old      string
+		updated  string
+		expected []Edit
+	}{
+		{"Identical", "a\nb\nc\n", "a\nb\nc\n", nil},
+		{"Inserted lines", "a\nb\nc\n", "a\nx\ny\nb\nc\n", []Edit{lineEdit(1, 1, "", "x\ny", 0, 2)}},
+		{"Deleted line", "a\nb\nc\n", "a\nc\n", []Edit{lineEdit(1, 1, "b", "", 1, 0)}},
+		{"Changed line", "a\nb\nc\n", "a\nB\nc\n", []Edit{lineEdit(1, 1, "b", "B", 1, 1)}},
+		{"Deleted empty line", "a\n\nb\n", "a\nb\n", []Edit{lineEdit(1, 1, "", "", 1, 0)}},
+		{"Old stream ends first", "a\nb\n", "a\nb\nc\nd\n", []Edit{lineEdit(2, 2, "", "c\nd", 0, 2)}},
+		{"Updated stream ends first", "a\nb\nc\nd\n", "a\nb\n", []Edit{lineEdit(2, 2, "c\nd", "", 2, 0)}},
+		{"Changed tail", "a\nb\nc\n", "a\nx\ny\n", []Edit{lineEdit(1, 1, "b\nc", "x\ny", 2, 2)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits, err := collect(tt.old, tt.updated, 3)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+
+	// Test that an insertion longer than the lookahead is reported line by line before resynchronizing
+	t.Run("Insertion beyond the lookahead", func(t *testing.T) {
+		edits, err := collect("a\nb\nc\nd\ne\n", "a\n1\n2\n3\n4\nb\nc\nd\ne\n", 2)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) == 0 || edits[0].Start != 1 {
+			t.Fatalf("Test failed. Expected: edits from line 1 Got: %+v", edits)
+		}
+		// Rebuild the updated lines from the old ones and the line edits
+		oldLines := []string{"a", "b", "c", "d", "e"}
+		var lines []string
+		pos := 0
+		for _, edit := range edits {
+			lines = append(lines, oldLines[pos:edit.Start]...)
+			pos = edit.Start
+			pos += edit.OldLen
+			if edit.Op != Deleted {
+				lines = append(lines, strings.Split(edit.New, "\n")...)
+			}
+		}
+		lines = append(lines, oldLines[pos:]...)
+		if updated := strings.Join(lines, "\n"); updated != "a\n1\n2\n3\n4\nb\nc\nd\ne" {
+			t.Errorf("Test failed. Expected the updated lines Got: %q %+v", updated, edits)
+		}
+	})
+
+	// Test that a multi-line change gives the same edits as DiffLines, lengths counted in lines
+	t.Run("Same edits as DiffLines", func(t *testing.T) {
+		old, updated := "a\nb\nc\nd", "a\nx\ny\nz\nd"
+		edits, err := collect(old, updated, 3)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := DiffLines(strings.Split(old, "\n"), strings.Split(updated, "\n"))
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that an error of emit stops the comparison
+	t.Run("Emit error", func(t *testing.T) {
+		stop := errors.New("stop")
+		oldScanner, newScanner := scanners("a\nb\n", "x\ny\n")
+		if err := DiffLineStream(oldScanner, newScanner, 2, func(Edit) error { return stop }); err != stop {
+			t.Errorf("Test failed. Expected: %v Got: %v", stop, err)
+		}
+	})
+
+	// Test that a lookahead below one line is rejected
+	t.Run("Invalid lookahead", func(t *testing.T) {
+		oldScanner, newScanner := scanners("a", "b")
+		if err := DiffLineStream(oldScanner, newScanner, 0, func(Edit) error { return nil }); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+}
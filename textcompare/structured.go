@@ -0,0 +1,149 @@
+package textcompare
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// jsonWindow is the window size used to compare the string values of JSON
+// documents, which are usually short.
+const jsonWindow = 1
+
+// missingValue stands for the value of a key or element absent from one of
+// the documents, which unlike a JSON null has no content.
+type missingValue struct{}
+
+// DiffJSON compares two JSON objects key by key instead of character by
+// character, so reformatting and key order do not show up as changes. Every
+// edit carries the Path of the value it changes, such as "a.b[2]". Keys and
+// array elements present on one side only are reported as added or deleted
+// with their JSON encoding as content, at Start 0. Changed string values are compared by
+// the engine, and their edits start at rune indexes into the string. Any
+// other changed value is reported as a single modification of its JSON encoding.
+func DiffJSON(old, updated string) (edits []Edit, err error) {
+	defer recoverPanic(&err)
+	var oldDoc, updatedDoc map[string]interface{}
+	if err := json.Unmarshal([]byte(old), &oldDoc); err != nil {
+		return nil, &CustomError{message: fmt.Sprintf("cannot parse old JSON document: %v", err)}
+	}
+	if err := json.Unmarshal([]byte(updated), &updatedDoc); err != nil {
+		return nil, &CustomError{message: fmt.Sprintf("cannot parse updated JSON document: %v", err)}
+	}
+	return diffJSONObjects("", oldDoc, updatedDoc, nil)
+}
+
+// diffJSONObjects appends the edits between two objects to edits, visiting
+// their keys in sorted order so the result does not depend on map iteration.
+func diffJSONObjects(path string, old, updated map[string]interface{}, edits []Edit) ([]Edit, error) {
+	keys := make([]string, 0, len(old)+len(updated))
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range updated {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var err error
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		oldValue, inOld := old[key]
+		updatedValue, inUpdated := updated[key]
+		switch {
+		case !inUpdated:
+			edits, err = appendJSONEdit(edits, keyPath, oldValue, missingValue{})
+		case !inOld:
+			edits, err = appendJSONEdit(edits, keyPath, missingValue{}, updatedValue)
+		default:
+			edits, err = diffJSONValues(keyPath, oldValue, updatedValue, edits)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return edits, nil
+}
+
+// diffJSONArrays appends the edits between two arrays to edits, comparing
+// their elements position by position.
+func diffJSONArrays(path string, old, updated []interface{}, edits []Edit) ([]Edit, error) {
+	var err error
+	for i := 0; i < max(len(old), len(updated)) && err == nil; i++ {
+		elementPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(updated):
+			edits, err = appendJSONEdit(edits, elementPath, old[i], missingValue{})
+		case i >= len(old):
+			edits, err = appendJSONEdit(edits, elementPath, missingValue{}, updated[i])
+		default:
+			edits, err = diffJSONValues(elementPath, old[i], updated[i], edits)
+		}
+	}
+	return edits, err
+}
+
+// diffJSONValues appends the edits between two values found at path.
+func diffJSONValues(path string, old, updated interface{}, edits []Edit) ([]Edit, error) {
+	switch oldValue := old.(type) {
+	case map[string]interface{}:
+		if updatedValue, ok := updated.(map[string]interface{}); ok {
+			return diffJSONObjects(path, oldValue, updatedValue, edits)
+		}
+	case []interface{}:
+		if updatedValue, ok := updated.([]interface{}); ok {
+			return diffJSONArrays(path, oldValue, updatedValue, edits)
+		}
+	case string:
+		if updatedValue, ok := updated.(string); ok {
+			stringEdits, err := DiffEdits(oldValue, updatedValue, jsonWindow)
+			if err != nil {
+				return nil, err
+			}
+			for _, edit := range stringEdits {
+				edit.Path = path
+				edits = append(edits, edit)
+			}
+			return edits, nil
+		}
+	}
+	if reflect.DeepEqual(old, updated) {
+		return edits, nil
+	}
+	return appendJSONEdit(edits, path, old, updated)
+}
+
+// appendJSONEdit appends the edit replacing the JSON encoding of old with the
+// one of updated.
+func appendJSONEdit(edits []Edit, path string, old, updated interface{}) ([]Edit, error) {
+	previous, err := encodeJSONValue(old)
+	if err != nil {
+		return nil, err
+	}
+	next, err := encodeJSONValue(updated)
+	if err != nil {
+		return nil, err
+	}
+	edit := newEdit(0, previous, next)
+	edit.Path = path
+	return append(edits, edit), nil
+}
+
+// encodeJSONValue returns the JSON encoding of value, or nothing for a
+// missing value.
+func encodeJSONValue(value interface{}) (string, error) {
+	if _, ok := value.(missingValue); ok {
+		return "", nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", &CustomError{message: fmt.Sprintf("cannot encode JSON value: %v", err)}
+	}
+	return string(data), nil
+}
@@ -0,0 +1,77 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffJSON(t *testing.T) {
+	// Test that reformatting and reordering keys is not a change
+	t.Run("Reformatted document", func(t *testing.T) {
+		edits, err := DiffJSON(`{"a": 1, "b": {"c": "x"}}`, "{\n  \"b\": {\"c\": \"x\"},\n  \"a\": 1\n}")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if len(edits) != 0 {
+			t.Errorf("Test failed. Expected: no edits Got: %+v", edits)
+		}
+	})
+
+	// Test that keys only present in the updated document are added
+	t.Run("Added keys", func(t *testing.T) {
+		edits, err := DiffJSON(`{"a": {"b": [1, 2]}}`, `{"a": {"b": [1, 2, 3], "c": true}, "d": "x"}`)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{
+			{Op: Added, Path: "a.b[2]", New: "3", NewLen: 1},
+			{Op: Added, Path: "a.c", New: "true", NewLen: 4},
+			{Op: Added, Path: "d", New: `"x"`, NewLen: 3},
+		}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that keys only present in the old document are deleted
+	t.Run("Removed keys", func(t *testing.T) {
+		edits, err := DiffJSON(`{"a": {"b": null, "c": [1]}, "d": 2}`, `{"a": {"c": []}}`)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{
+			{Op: Deleted, Path: "a.b", Old: "null", OldLen: 4},
+			{Op: Deleted, Path: "a.c[0]", Old: "1", OldLen: 1},
+			{Op: Deleted, Path: "d", Old: "2", OldLen: 1},
+		}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that changed scalars are modified and changed strings are compared by the engine
+	t.Run("Changed scalar values", func(t *testing.T) {
+		edits, err := DiffJSON(`{"port": 8080, "host": "localhost", "tags": ["a", {"on": false}]}`,
+			`{"port": 9090, "host": "localhast", "tags": ["a", {"on": true}]}`)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		expected := []Edit{
+			{Op: Modified, Path: "host", Start: 6, NewStart: 6, Old: "o", New: "a", OldLen: 1, NewLen: 1},
+			{Op: Modified, Path: "port", Old: "8080", New: "9090", OldLen: 4, NewLen: 4},
+			{Op: Modified, Path: "tags[1].on", Old: "false", New: "true", OldLen: 5, NewLen: 4},
+		}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that invalid documents are reported
+	t.Run("Invalid document", func(t *testing.T) {
+		for _, pair := range [][2]string{{`{"a":`, `{}`}, {`{}`, `[1, 2]`}} {
+			if _, err := DiffJSON(pair[0], pair[1]); err == nil {
+				t.Errorf("Test failed. Documents %q %q Expected an error", pair[0], pair[1])
+			}
+		}
+	})
+}
@@ -0,0 +1,255 @@
+package textcompare
+
+import (
+	"fmt"
+	"slices"
+)
+
+type TextSearch struct {
+	buffer     []rune
+	bytes      string // the text compared byte by byte when bytewise, in place of buffer
+	bytewise   bool
+	hash       int
+	index      int
+	length     int
+	prime      int
+	base       int
+	windowSize int
+	highPower  int // base^(windowSize-1) mod prime, weight of the oldest character
+	lastError  error
+	stats      *DiffStats // counts the hash operations when set
+}
+
+// DiffStats counts the hash operations performed by a comparison.
+type DiffStats struct {
+	// Slides is the number of times a window was rolled one character.
+	Slides int
+	// SetStarts is the number of times a window was hashed from scratch.
+	SetStarts int
+	// Collisions is the number of times two different windows had the same
+	// hash, so their content had to be compared to tell them apart. Many
+	// collisions call for a larger prime.
+	Collisions int
+}
+
+type CustomError struct {
+	message string
+}
+
+func (e *CustomError) Error() string {
+	return e.message
+}
+
+// Obtain the rest of the buffer, from the start of the current window to the
+// end of the text. Use CurrentWindow for the window alone.
+func (ts *TextSearch) GetWindowString() string {
+	if ts.bytewise {
+		return ts.bytes[ts.index:]
+	}
+	return string(ts.buffer[ts.index:])
+}
+
+// Obtain the current window, which is shorter than the window size only when
+// it reaches past the end of the text
+func (ts *TextSearch) CurrentWindow() string {
+	start, end := ts.window()
+	if ts.bytewise {
+		return ts.bytes[start:end]
+	}
+	return string(ts.buffer[start:end])
+}
+
+// Slide the window to calculate the hash of the next text segment.
+// The window can move while the character after it exists, that is while
+// index+windowSize < length, so the last full window is still reached and
+// hashed; only a call made once it is the current window reports EOF.
+func (ts *TextSearch) Slide() (*CustomError, int, string) {
+	err := ts.advance()
+	return err, ts.hash, ts.GetWindowString()
+}
+
+// advance slides the window like Slide without building the rest of the
+// buffer, which the searches of a comparison never read.
+func (ts *TextSearch) advance() *CustomError {
+	if ts.index+ts.windowSize >= ts.length {
+		err := &CustomError{message: "EOF"}
+		ts.lastError = err
+		return err
+	}
+	if ts.stats != nil {
+		ts.stats.Slides++
+	}
+	ts.roll()
+	return nil
+}
+
+// roll updates the hash to the window starting one character further.
+func (ts *TextSearch) roll() {
+	// Remove the contribution of the oldest character.
+	ts.hash = (ts.hash - ts.value(ts.index)*ts.highPower) % ts.prime
+	if ts.hash < 0 {
+		ts.hash += ts.prime // Ensure that the result is positive
+	}
+
+	// Add the contribution of the new character
+	ts.hash = (ts.hash*ts.base + ts.value(ts.index+ts.windowSize)) % ts.prime
+	ts.index++
+}
+
+// value returns the character at i reduced modulo the prime, so that every
+// product of the hash arithmetic stays below prime squared.
+func (ts *TextSearch) value(i int) int {
+	if ts.bytewise {
+		return int(ts.bytes[i]) % ts.prime
+	}
+	return int(ts.buffer[i]) % ts.prime
+}
+
+// window returns the bounds of the characters covered by the current window.
+func (ts *TextSearch) window() (start, end int) {
+	end = min(ts.index+ts.windowSize, ts.length)
+	return min(ts.index, end), end
+}
+
+// sameContent reports whether the windows of ts and other cover equal
+// characters.
+func (ts *TextSearch) sameContent(other *TextSearch) bool {
+	start1, end1 := ts.window()
+	start2, end2 := other.window()
+	if ts.bytewise && other.bytewise {
+		return ts.bytes[start1:end1] == other.bytes[start2:end2]
+	}
+	return slices.Equal(ts.buffer[start1:end1], other.buffer[start2:end2])
+}
+
+// sameWindow reports whether two searches are positioned on equal windows.
+// Equal hashes are confirmed by comparing the characters, since different
+// windows can collide on the same hash.
+func sameWindow(ts1, ts2 *TextSearch) bool {
+	if ts1.hash != ts2.hash {
+		return false
+	}
+	if !ts1.sameContent(ts2) {
+		if ts1.stats != nil {
+			ts1.stats.Collisions++
+		}
+		return false
+	}
+	return true
+}
+
+// Get the current hash of the text
+func (ts *TextSearch) GetHash() int {
+	return ts.hash
+}
+
+// Create a text buffer with a specific window size.
+// The input is stored as runes so that multibyte UTF-8 characters are hashed
+// and indexed as a single unit. A non-positive window size is rejected and a
+// window larger than the input is clamped to the input length.
+func (ts *TextSearch) CreateBuffer(input string, windowSize int) error {
+	return ts.CreateBufferWithConfig(input, windowSize, DefaultHashConfig)
+}
+
+// Create a text buffer hashed with the given prime and base instead of the
+// defaults. Zero fields of cfg use DefaultHashConfig.
+func (ts *TextSearch) CreateBufferWithConfig(input string, windowSize int, cfg HashConfig) error {
+	if err := cfg.validate(); err != nil {
+		ts.lastError = err
+		return err
+	}
+	// Reuse the buffer of a previous text when it is large enough
+	ts.buffer = ts.buffer[:0]
+	for _, r := range input {
+		ts.buffer = append(ts.buffer, r)
+	}
+	ts.bytes, ts.bytewise = "", false
+	return ts.configure(len(ts.buffer), windowSize, cfg)
+}
+
+// createBytes prepares the search of text byte by byte, every byte one
+// character whatever the encoding, as DiffBytes compares. The bytes are
+// hashed in place, without being decoded into the buffer.
+func (ts *TextSearch) createBytes(text string, windowSize int, cfg HashConfig) error {
+	if err := cfg.validate(); err != nil {
+		ts.lastError = err
+		return err
+	}
+	ts.bytes, ts.bytewise = text, true
+	return ts.configure(len(text), windowSize, cfg)
+}
+
+// configure sets the hash parameters for a text of length characters and
+// places the window at its start, with its hash left to SetStart.
+func (ts *TextSearch) configure(length, windowSize int, cfg HashConfig) error {
+	cfg = cfg.withDefaults()
+	ts.hash = 0
+	ts.index = 0
+	ts.prime = cfg.Prime
+	ts.base = cfg.Base % cfg.Prime
+	ts.length = length
+	ts.lastError = nil
+	if windowSize <= 0 {
+		ts.lastError = &CustomError{message: fmt.Sprintf("window size must be positive, got %d", windowSize)}
+		return ts.lastError
+	}
+	if windowSize > ts.length {
+		windowSize = ts.length
+	}
+	ts.windowSize = windowSize
+	ts.highPower = modPow(ts.base, windowSize-1, ts.prime)
+	return nil
+}
+
+// Set the starting point of the window. A window that does not fit in the
+// buffer is rejected and recorded as the last error, so searches treat it as
+// the end of the text.
+func (ts *TextSearch) SetStart(index, window int) error {
+	ts.hash = 0
+	ts.lastError = nil
+	if window <= 0 || index < 0 || index+window > ts.length {
+		ts.lastError = &CustomError{message: fmt.Sprintf("window [%d, %d) out of range for a text of %d characters", index, index+window, ts.length)}
+		return ts.lastError
+	}
+	if ts.stats != nil {
+		ts.stats.SetStarts++
+	}
+	ts.index = index
+	ts.windowSize = window
+	ts.highPower = modPow(ts.base, window-1, ts.prime)
+	for i := index; i < index+window; i++ {
+		ts.hash = (ts.hash*ts.base + ts.value(i)) % ts.prime
+	}
+	return nil
+}
+
+// ComputeHash returns the polynomial hash of s, the same value a TextSearch
+// configured with prime and base holds for a window covering s. The prime
+// must be at least 2 and the base positive.
+func ComputeHash(s string, prime, base int) int {
+	return hashRunes([]rune(s), prime, base%prime)
+}
+
+// hashRunes hashes runes from scratch with a base already reduced modulo the
+// prime.
+func hashRunes(runes []rune, prime, base int) int {
+	hash := 0
+	for _, r := range runes {
+		hash = (hash*base + int(r)%prime) % prime
+	}
+	return hash
+}
+
+// modPow computes base^exp mod m by repeated squaring.
+func modPow(base, exp, m int) int {
+	result := 1 % m
+	base %= m
+	for exp > 0 {
+		if exp&1 == 1 {
+			result = result * base % m
+		}
+		base = base * base % m
+		exp >>= 1
+	}
+	return result
+}
@@ -0,0 +1,392 @@
+package textcompare
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestModPow(t *testing.T) {
+	// Test the precomputed multiplier matches the floating point power for small windows
+	for window := 1; window <= 4; window++ {
+		expected := int(math.Pow(256, float64(window-1))) % 5381
+		if got := modPow(256, window-1, 5381); got != expected {
+			t.Errorf("Test failed. Window %d Expected: %d Got: %d", window, expected, got)
+		}
+	}
+}
+
+func TestSlideHash(t *testing.T) {
+	// Test that sliding produces the same hash as hashing the window from scratch
+	var sliding, direct TextSearch
+	text := "the quick brown fox jumps over the lazy dog"
+	windowSize := 16
+	sliding.CreateBuffer(text, windowSize)
+	sliding.SetStart(0, windowSize)
+	direct.CreateBuffer(text, windowSize)
+	for i := 1; i+windowSize <= len(text); i++ {
+		if err, _, _ := sliding.Slide(); err != nil {
+			break
+		}
+		direct.SetStart(i, windowSize)
+		if sliding.GetHash() != direct.GetHash() {
+			t.Fatalf("Test failed. Index %d Expected: %d Got: %d", i, direct.GetHash(), sliding.GetHash())
+		}
+	}
+}
+
+// rollPow is the previous rolling step, which recomputed the multiplier with math.Pow on every call.
+func rollPow(ts *TextSearch) {
+	ts.hash = (ts.hash - int(ts.buffer[ts.index])*int(math.Pow(256, float64(ts.windowSize-1)))) % ts.prime
+	if ts.hash < 0 {
+		ts.hash += ts.prime
+	}
+	ts.hash = (ts.hash*256 + int(ts.buffer[ts.index+ts.windowSize])) % ts.prime
+	ts.index++
+}
+
+func benchmarkRoll(b *testing.B, roll func(ts *TextSearch)) {
+	var ts TextSearch
+	windowSize := 4
+	ts.CreateBuffer(strings.Repeat("lorem ipsum dolor sit amet ", 1<<20/27), windowSize)
+	ts.SetStart(0, windowSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ts.index+ts.windowSize >= ts.length {
+			ts.SetStart(0, windowSize)
+		}
+		roll(&ts)
+	}
+}
+
+func BenchmarkSlidePow(b *testing.B) {
+	benchmarkRoll(b, rollPow)
+}
+
+func BenchmarkSlide(b *testing.B) {
+	benchmarkRoll(b, (*TextSearch).roll)
+}
+
+func benchmarkComputeHash(b *testing.B, windowSize int) {
+	window := strings.Repeat("lorem ipsum dolor sit amet ", windowSize/27+1)[:windowSize]
+	for i := 0; i < b.N; i++ {
+		ComputeHash(window, DefaultHashConfig.Prime, DefaultHashConfig.Base)
+	}
+}
+
+func BenchmarkComputeHash4(b *testing.B) {
+	benchmarkComputeHash(b, 4)
+}
+
+func BenchmarkComputeHash16(b *testing.B) {
+	benchmarkComputeHash(b, 16)
+}
+
+func BenchmarkComputeHash64(b *testing.B) {
+	benchmarkComputeHash(b, 64)
+}
+
+func TestComputeHash(t *testing.T) {
+	// Test that hashing a window on its own matches the hash SetStart computes
+	configs := []HashConfig{DefaultHashConfig, {Prime: 1000000007, Base: 131}, {Prime: MaxPrime, Base: RuneBase}}
+	text := "the quick brown fox jumps over the año nuevo 中文 \U0001F600"
+	runes := []rune(text)
+	for _, cfg := range configs {
+		for windowSize := 1; windowSize <= 8; windowSize++ {
+			var ts TextSearch
+			ts.CreateBufferWithConfig(text, windowSize, cfg)
+			for i := 0; i+windowSize <= len(runes); i++ {
+				ts.SetStart(i, windowSize)
+				if got := ComputeHash(string(runes[i:i+windowSize]), cfg.Prime, cfg.Base); got != ts.GetHash() {
+					t.Fatalf("Test failed. Config %+v Window %d Index %d Expected: %d Got: %d", cfg, windowSize, i, ts.GetHash(), got)
+				}
+			}
+		}
+	}
+
+	// Test that the hash of a known window is stable
+	t.Run("Known value", func(t *testing.T) {
+		expected := (int('a')*256 + int('b')) % 5381
+		if got := ComputeHash("ab", 5381, 256); got != expected {
+			t.Errorf("Test failed. Expected: %d Got: %d", expected, got)
+		}
+	})
+}
+
+func TestWindowBounds(t *testing.T) {
+	// Test that invalid windows are rejected instead of panicking
+	for _, window := range []int{0, -1, 6} {
+		var ts TextSearch
+		ts.CreateBuffer("hello", 2)
+		err := ts.SetStart(0, window)
+		if _, ok := err.(*CustomError); !ok {
+			t.Errorf("Test failed. Window %d Expected a *CustomError Got: %v", window, err)
+		}
+		if ts.lastError == nil {
+			t.Errorf("Test failed. Window %d Expected the error to be recorded", window)
+		}
+	}
+
+	// Test that a window starting past the end is rejected
+	t.Run("Window past the end", func(t *testing.T) {
+		var ts TextSearch
+		ts.CreateBuffer("hello", 2)
+		if err := ts.SetStart(4, 2); err == nil {
+			t.Errorf("Test failed. Expected an error")
+		}
+	})
+
+	// Test that CreateBuffer rejects non-positive windows
+	for _, window := range []int{0, -1} {
+		var ts TextSearch
+		if err := ts.CreateBuffer("hello", window); err == nil {
+			t.Errorf("Test failed. Window %d Expected an error", window)
+		}
+	}
+
+	// Test that CreateBuffer clamps windows exceeding the buffer
+	t.Run("Oversized window is clamped", func(t *testing.T) {
+		var ts TextSearch
+		if err := ts.CreateBuffer("hello", 10); err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if ts.windowSize != 5 {
+			t.Errorf("Test failed. Expected: 5 Got: %d", ts.windowSize)
+		}
+	})
+
+	// Test that comparisons with oversized windows do not panic
+	t.Run("SearchFirstDif with a window larger than one text", func(t *testing.T) {
+		if _, _, _, err := SearchFirstDif("ab", "abcdef", 4); err != nil {
+			t.Errorf("Test failed. Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestHashCollision(t *testing.T) {
+	// "AA" and "VF" share the same hash for a window of 2 under prime 5381
+	var ts1, ts2 TextSearch
+	ts1.CreateBuffer("AA", 2)
+	ts1.SetStart(0, 2)
+	ts2.CreateBuffer("VF", 2)
+	ts2.SetStart(0, 2)
+	if ts1.GetHash() != ts2.GetHash() {
+		t.Fatalf("Test failed. Expected colliding hashes Got: %d %d", ts1.GetHash(), ts2.GetHash())
+	}
+
+	// Test that colliding windows are not considered equal
+	t.Run("Colliding windows differ", func(t *testing.T) {
+		if sameWindow(&ts1, &ts2) {
+			t.Errorf("Test failed. Expected the windows to differ")
+		}
+	})
+
+	// Test that the diff still reports the colliding content
+	t.Run("Diff of colliding content", func(t *testing.T) {
+		tests := [][2]string{{"AA", "VF"}, {"xxAA", "xxVF"}, {"AAyy", "VFyy"}, {"AFAAVAF", "FAVFFVV"}}
+		for _, tt := range tests {
+			delta := mustCheckString(t, tt[0], tt[1], 2, 0)
+			if got := ReplaceDelta(tt[0], delta); got != tt[1] {
+				t.Errorf("Test failed. Expected: %s Got: %s (delta %q)", tt[1], got, delta)
+			}
+		}
+	})
+}
+
+func TestSlideFinalWindow(t *testing.T) {
+	// Test that sliding reaches the last full window before reporting EOF
+	for windowSize := 1; windowSize <= 5; windowSize++ {
+		var ts TextSearch
+		ts.CreateBuffer("hello", windowSize)
+		ts.SetStart(0, windowSize)
+		slides := 0
+		for {
+			err, _, _ := ts.Slide()
+			if err != nil {
+				break
+			}
+			slides++
+		}
+		if expected := 5 - windowSize; slides != expected || ts.index != expected {
+			t.Errorf("Test failed. Window %d Expected: %d slides Got: %d ending at %d", windowSize, expected, slides, ts.index)
+		}
+		var last TextSearch
+		last.CreateBuffer("hello", windowSize)
+		last.SetStart(5-windowSize, windowSize)
+		if ts.GetHash() != last.GetHash() {
+			t.Errorf("Test failed. Window %d Expected: %d Got: %d", windowSize, last.GetHash(), ts.GetHash())
+		}
+	}
+
+	// Test that a single changed character in the final window is found
+	tests := [][2]string{
+		{"hello worl", "hello word"},
+		{"hello world", "hello worlx"},
+		{"abc", "abd"},
+	}
+	for _, tt := range tests {
+		for windowSize := 1; windowSize <= 3; windowSize++ {
+			last := len(tt[0]) - 1
+			_, index, isEnd, err := SearchFirstDif(tt[0], tt[1], windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			if index != last || isEnd {
+				t.Errorf("Test failed. %q Window %d Expected: %d Got: %d (end %t)", tt, windowSize, last, index, isEnd)
+			}
+			edits, err := DiffEdits(tt[0], tt[1], windowSize)
+			if err != nil {
+				t.Fatalf("Test failed. Unexpected error: %v", err)
+			}
+			expected := []Edit{{Op: Modified, Start: last, NewStart: last, Old: tt[0][last:], New: tt[1][last:], OldLen: 1, NewLen: 1}}
+			if !reflect.DeepEqual(edits, expected) {
+				t.Errorf("Test failed. %q Window %d Expected: %+v Got: %+v", tt, windowSize, expected, edits)
+			}
+		}
+	}
+}
+
+func TestDiffStats(t *testing.T) {
+	// Test that the counters increment on each slide and each fresh hash
+	t.Run("Counts slides", func(t *testing.T) {
+		var ts TextSearch
+		var stats DiffStats
+		ts.CreateBuffer("hello", 2)
+		ts.stats = &stats
+		ts.SetStart(0, 2)
+		for i := 1; i <= 3; i++ {
+			ts.Slide()
+			if stats.Slides != i {
+				t.Errorf("Test failed. Expected: %d Got: %d", i, stats.Slides)
+			}
+		}
+		if stats.SetStarts != 1 {
+			t.Errorf("Test failed. Expected: 1 Got: %d", stats.SetStarts)
+		}
+	})
+
+	// Test that a comparison reports the hash operations it performed
+	t.Run("DiffWithStats", func(t *testing.T) {
+		edits, stats, err := DiffWithStats("the quick brown fox", "the quack brown fix", DiffOptions{WindowSize: 2})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits("the quick brown fox", edits); got != "the quack brown fix" {
+			t.Errorf("Test failed. Expected: the quack brown fix Got: %s", got)
+		}
+		if stats.Slides == 0 || stats.SetStarts == 0 {
+			t.Errorf("Test failed. Expected: nonzero counters Got: %+v", stats)
+		}
+	})
+
+	// Test that identical texts need no hashing
+	t.Run("Identical texts", func(t *testing.T) {
+		_, stats, _ := DiffWithStats("hello", "hello", DiffOptions{WindowSize: 2})
+		if stats != (DiffStats{}) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", DiffStats{}, stats)
+		}
+	})
+}
+
+func TestCurrentWindow(t *testing.T) {
+	// Test that the window has the window size away from the end of the text
+	t.Run("Window length", func(t *testing.T) {
+		var ts TextSearch
+		text := "hello world"
+		windowSize := 3
+		ts.CreateBuffer(text, windowSize)
+		ts.SetStart(0, windowSize)
+		for i := 0; ; i++ {
+			if got := ts.CurrentWindow(); got != text[i:i+windowSize] {
+				t.Errorf("Test failed. Index %d Expected: %s Got: %s", i, text[i:i+windowSize], got)
+			}
+			if err, _, _ := ts.Slide(); err != nil {
+				break
+			}
+		}
+	})
+
+	// Test that GetWindowString still returns the rest of the buffer
+	t.Run("Rest of the buffer", func(t *testing.T) {
+		var ts TextSearch
+		ts.CreateBuffer("hello world", 3)
+		ts.SetStart(6, 3)
+		if got := ts.GetWindowString(); got != "world" {
+			t.Errorf("Test failed. Expected: world Got: %s", got)
+		}
+		if got := ts.CurrentWindow(); got != "wor" {
+			t.Errorf("Test failed. Expected: wor Got: %s", got)
+		}
+	})
+
+	// Test that a window past the end of the text is clamped
+	t.Run("Clamped at the end", func(t *testing.T) {
+		ts := TextSearch{buffer: []rune("hello"), length: 5, index: 3, windowSize: 4}
+		if got := ts.CurrentWindow(); got != "lo" {
+			t.Errorf("Test failed. Expected: lo Got: %s", got)
+		}
+	})
+}
+
+func TestLargeCodePoints(t *testing.T) {
+	text := "\U0001F600\U0001F389\U0001D11E中文\U00010348\U0010FFFD\U0001F600\U0001F389"
+	configs := []HashConfig{DefaultHashConfig, {Prime: MaxPrime, Base: RuneBase}, {Prime: 1000000007, Base: RuneBase}}
+
+	// Test that sliding over large code points matches hashing every window from scratch
+	for _, cfg := range configs {
+		for windowSize := 1; windowSize <= 4; windowSize++ {
+			var sliding, direct TextSearch
+			sliding.CreateBufferWithConfig(text, windowSize, cfg)
+			sliding.SetStart(0, windowSize)
+			direct.CreateBufferWithConfig(text, windowSize, cfg)
+			for i := 1; ; i++ {
+				if err, _, _ := sliding.Slide(); err != nil {
+					break
+				}
+				direct.SetStart(i, windowSize)
+				if sliding.GetHash() != direct.GetHash() {
+					t.Fatalf("Test failed. Config %+v Window %d Index %d Expected: %d Got: %d", cfg, windowSize, i, direct.GetHash(), sliding.GetHash())
+				}
+				if h := sliding.GetHash(); h < 0 || h >= cfg.Prime {
+					t.Fatalf("Test failed. Expected: a hash in [0, %d) Got: %d", cfg.Prime, h)
+				}
+			}
+		}
+	}
+
+	// Test that equal windows of large code points hash equally in different texts
+	t.Run("Equal windows", func(t *testing.T) {
+		var ts1, ts2 TextSearch
+		cfg := HashConfig{Prime: MaxPrime, Base: RuneBase}
+		ts1.CreateBufferWithConfig(text, 2, cfg)
+		ts1.SetStart(0, 2)
+		ts2.CreateBufferWithConfig(text, 2, cfg)
+		ts2.SetStart(7, 2)
+		if !sameWindow(&ts1, &ts2) {
+			t.Errorf("Test failed. Expected: %q Got: %q", ts1.CurrentWindow(), ts2.CurrentWindow())
+		}
+	})
+
+	// Test that the base adapts to the characters of the texts
+	t.Run("Alphabet base", func(t *testing.T) {
+		if got := alphabetBase("hello", "año"); got != ByteBase {
+			t.Errorf("Test failed. Expected: %d Got: %d", ByteBase, got)
+		}
+		if got := alphabetBase("hello", "中文"); got != RuneBase {
+			t.Errorf("Test failed. Expected: %d Got: %d", RuneBase, got)
+		}
+	})
+
+	// Test that comparisons of large code points round trip with the largest prime
+	t.Run("Diff with the largest prime", func(t *testing.T) {
+		updated := "\U0001F600\U0001F389\U0001D11E中\U0001F680文\U00010348\U0010FFFD\U0001F600\U0001F389"
+		edits, err := DiffWithOptions(text, updated, DiffOptions{WindowSize: 2, Hash: HashConfig{Prime: MaxPrime}})
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error: %v", err)
+		}
+		if got := applyEdits(text, edits); got != updated {
+			t.Errorf("Test failed. Expected: %s Got: %s", updated, got)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package textcompare
+
+import "strings"
+
+// SplitFunc splits a text into the tokens compared by DiffSplit. Joining the
+// tokens should give back the text, so delimiters are best kept on the token
+// they end, as strings.SplitAfter does.
+type SplitFunc func(string) []string
+
+// DiffTokens compares two token sequences. Every token is an atomic unit, so
+// a changed token is reported as a whole. Edit positions are rune indexes
+// into the text formed by joining the old tokens and every edit starts and
+// ends on a token boundary.
+func DiffTokens(old, updated []string) []Edit {
+	return diffTokensAt(old, updated)
+}
+
+// DiffSplit compares two texts token by token, using split to break both
+// texts into tokens. Edit positions are rune indexes into old when joining
+// the tokens gives back the text.
+func DiffSplit(old, updated string, split SplitFunc) []Edit {
+	return DiffTokens(split(old), split(updated))
+}
+
+// DiffBy compares two slices element by element, using key to identify every
+// element. Elements with equal keys are equal, so the key should hold every
+// field whose changes matter. Edit positions, NewStart included, are element
+// indexes, OldLen and NewLen count elements, and the content of each edit
+// holds the keys of the affected elements joined with "\n".
+func DiffBy[T any](old, updated []T, key func(T) string) []Edit {
+	edits, table := compareTokens(keysOf(old, key), keysOf(updated, key))
+	for i, edit := range edits {
+		edits[i].Old = strings.Join(table.decode(edit.Old), "\n")
+		edits[i].New = strings.Join(table.decode(edit.New), "\n")
+	}
+	return edits
+}
+
+func keysOf[T any](elements []T, key func(T) string) []string {
+	keys := make([]string, len(elements))
+	for i, element := range elements {
+		keys[i] = key(element)
+	}
+	return keys
+}
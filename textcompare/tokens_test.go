@@ -0,0 +1,117 @@
+package textcompare
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func splitFields(text string) []string {
+	return strings.SplitAfter(text, ",")
+}
+
+func TestDiffSplit(t *testing.T) {
+	// Test that a changed sentence is reported as a whole
+	t.Run("Sentence splitter", func(t *testing.T) {
+		oldText := "The sky is blue. Grass is green. Snow is white."
+		updatedText := "The sky is blue. Grass is yellow. Snow is white."
+		edits := DiffSplit(oldText, updatedText, splitSentences)
+		expected := []Edit{{Op: Modified, Start: 17, NewStart: 17, Old: "Grass is green. ", New: "Grass is yellow. ", OldLen: 16, NewLen: 17}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that an added sentence is reported at a sentence boundary
+	t.Run("Added sentence", func(t *testing.T) {
+		oldText := "One. Two. Three."
+		updatedText := "One. Two. Two and a half. Three."
+		edits := DiffSplit(oldText, updatedText, splitSentences)
+		expected := []Edit{{Op: Added, Start: 10, NewStart: 10, New: "Two and a half. ", NewLen: 16}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+
+	// Test that CSV fields are compared as units
+	t.Run("Comma splitter", func(t *testing.T) {
+		oldText := "id,name,email,age"
+		updatedText := "id,name,phone,age"
+		edits := DiffSplit(oldText, updatedText, splitFields)
+		expected := []Edit{{Op: Modified, Start: 8, NewStart: 8, Old: "email,", New: "phone,", OldLen: 6, NewLen: 6}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+		if got := applyEdits(oldText, edits); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	})
+
+	// Test that splitting into words matches DiffWords
+	t.Run("Word splitter", func(t *testing.T) {
+		oldText, updatedText := "the quick brown fox", "the slow brown fox jumps"
+		if got, expected := DiffSplit(oldText, updatedText, splitWords), DiffWords(oldText, updatedText); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, got)
+		}
+	})
+}
+
+func TestDiffTokens(t *testing.T) {
+	// Test that whole tokens are compared
+	t.Run("Removed token", func(t *testing.T) {
+		edits := DiffTokens([]string{"a", "bb", "ccc"}, []string{"a", "ccc"})
+		expected := []Edit{{Op: Deleted, Start: 1, NewStart: 1, Old: "bb", OldLen: 2}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
+
+func TestDiffBy(t *testing.T) {
+	type record struct{ ID, Val string }
+	byID := func(r record) string { return r.ID }
+	old := []record{{"1", "apple"}, {"2", "banana"}, {"3", "cherry"}, {"4", "date"}}
+	tests := []struct {
+		name     string
+		updated  []record
+		expected []Edit
+	}{
+		{
+			"Added record",
+			[]record{{"1", "apple"}, {"2", "banana"}, {"5", "elderberry"}, {"3", "cherry"}, {"4", "date"}},
+			[]Edit{{Op: Added, Start: 2, NewStart: 2, New: "5", NewLen: 1}},
+		},
+		{
+			"Deleted records",
+			[]record{{"1", "apple"}, {"4", "date"}},
+			[]Edit{{Op: Deleted, Start: 1, NewStart: 1, Old: "2\n3", OldLen: 2}},
+		},
+		{
+			"Replaced record",
+			[]record{{"1", "apple"}, {"2", "banana"}, {"7", "fig"}, {"4", "date"}},
+			[]Edit{{Op: Modified, Start: 2, NewStart: 2, Old: "3", New: "7", OldLen: 1, NewLen: 1}},
+		},
+		{
+			"Changed value with the same ID",
+			[]record{{"1", "apple"}, {"2", "blueberry"}, {"3", "cherry"}, {"4", "date"}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if edits := DiffBy(old, tt.updated, byID); !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+
+	// Test that a key holding every field reports changed values
+	t.Run("Key with the value", func(t *testing.T) {
+		updated := []record{{"1", "apple"}, {"2", "blueberry"}, {"3", "cherry"}, {"4", "date"}}
+		edits := DiffBy(old, updated, func(r record) string { return r.ID + "=" + r.Val })
+		expected := []Edit{{Op: Modified, Start: 1, NewStart: 1, Old: "2=banana", New: "2=blueberry", OldLen: 1, NewLen: 1}}
+		if !reflect.DeepEqual(edits, expected) {
+			t.Errorf("Test failed. Expected: %+v Got: %+v", expected, edits)
+		}
+	})
+}
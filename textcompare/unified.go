@@ -0,0 +1,205 @@
+package textcompare
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContextLines is the number of unchanged lines FormatUnified shows
+// around every change, as GNU diff does by default.
+const DefaultContextLines = 3
+
+// textLines splits a text into lines keeping the terminating newline, and
+// records the rune position where every line starts.
+type textLines struct {
+	lines  []string
+	starts []int
+	length int
+}
+
+func splitTextLines(text string) textLines {
+	var tl textLines
+	pos := 0
+	for len(text) > 0 {
+		line := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			line = text[:i+1]
+		}
+		tl.lines = append(tl.lines, line)
+		tl.starts = append(tl.starts, pos)
+		pos += utf8.RuneCountInString(line)
+		text = text[len(line):]
+	}
+	tl.length = pos
+	return tl
+}
+
+// lineOf returns the index of the line holding the rune at pos. A position at
+// the very end of a text terminated by a newline belongs to no line and
+// returns len(lines).
+func (tl textLines) lineOf(pos int) int {
+	if pos >= tl.length && tl.endsWithNewline() {
+		return len(tl.lines)
+	}
+	return sort.Search(len(tl.starts), func(i int) bool { return tl.starts[i] > pos }) - 1
+}
+
+func (tl textLines) endsWithNewline() bool {
+	return len(tl.lines) == 0 || strings.HasSuffix(tl.lines[len(tl.lines)-1], "\n")
+}
+
+// isLineStart reports whether pos is the first rune of a line or the end of
+// a text terminated by a newline.
+func (tl textLines) isLineStart(pos int) bool {
+	if pos >= tl.length {
+		return tl.endsWithNewline()
+	}
+	i := tl.lineAt(pos)
+	return i < len(tl.starts) && tl.starts[i] == pos
+}
+
+// lineAt returns the index of the line starting at pos, or len(lines) for
+// the end of the text.
+func (tl textLines) lineAt(pos int) int {
+	return sort.Search(len(tl.starts), func(i int) bool { return tl.starts[i] >= pos })
+}
+
+func (tl textLines) startOf(line int) int {
+	if line >= len(tl.starts) {
+		return tl.length
+	}
+	return tl.starts[line]
+}
+
+// lineBlock is a run of whole lines replaced between the old and new text.
+// Ranges are half-open line indexes.
+type lineBlock struct {
+	oldFrom, oldTo int
+	newFrom, newTo int
+}
+
+// lineBlocks groups edits into the whole lines they touch in both texts.
+func lineBlocks(oldLines, newLines textLines, edits []Edit) []lineBlock {
+	var blocks []lineBlock
+	var startOffsets, endOffsets []int
+	offset := 0
+	for _, edit := range edits {
+		oldLen := utf8.RuneCountInString(edit.Old)
+		from := oldLines.lineOf(edit.Start)
+		// The line right after the edit is untouched only when the edit ends
+		// on a line boundary in both texts, otherwise it was joined or split.
+		end := edit.Start + oldLen
+		to := oldLines.lineOf(end) + 1
+		if oldLines.isLineStart(end) && endsOnNewLine(edit, oldLines) {
+			to = oldLines.lineAt(end)
+		}
+		if from < 0 {
+			from = 0
+		}
+		if to > len(oldLines.lines) {
+			to = len(oldLines.lines)
+		}
+		if from > to {
+			from = to
+		}
+		before := offset
+		offset += utf8.RuneCountInString(edit.New) - oldLen
+		if n := len(blocks); n > 0 && from <= blocks[n-1].oldTo {
+			if to > blocks[n-1].oldTo {
+				blocks[n-1].oldTo = to
+			}
+			endOffsets[n-1] = offset
+			continue
+		}
+		blocks = append(blocks, lineBlock{oldFrom: from, oldTo: to})
+		startOffsets = append(startOffsets, before)
+		endOffsets = append(endOffsets, offset)
+	}
+	for i := range blocks {
+		blocks[i].newFrom = newLines.lineAt(oldLines.startOf(blocks[i].oldFrom) + startOffsets[i])
+		blocks[i].newTo = newLines.lineAt(oldLines.startOf(blocks[i].oldTo) + endOffsets[i])
+	}
+	return blocks
+}
+
+// endsOnNewLine reports whether the text following edit starts a new line in
+// the updated text.
+func endsOnNewLine(edit Edit, oldLines textLines) bool {
+	if edit.New != "" {
+		return strings.HasSuffix(edit.New, "\n")
+	}
+	return oldLines.isLineStart(edit.Start)
+}
+
+// FormatUnified renders edits as a GNU unified diff with DefaultContextLines
+// lines of context, so the result can be consumed by patch and other tools.
+func FormatUnified(old, updated string, edits []Edit) string {
+	return FormatUnifiedContext(old, updated, edits, DefaultContextLines)
+}
+
+// FormatUnifiedContext renders edits as a GNU unified diff showing context
+// unchanged lines around every change. Changes closer than twice the context
+// are grouped in the same hunk.
+func FormatUnifiedContext(old, updated string, edits []Edit, context int) string {
+	if len(edits) == 0 {
+		return ""
+	}
+	if context < 0 {
+		context = 0
+	}
+	oldLines, newLines := splitTextLines(old), splitTextLines(updated)
+	blocks := lineBlocks(oldLines, newLines, expandMoves(edits))
+
+	var sb strings.Builder
+	sb.WriteString("--- old\n+++ updated\n")
+	for len(blocks) > 0 {
+		// Collect the blocks sharing a hunk
+		n := 1
+		for n < len(blocks) && blocks[n].oldFrom-blocks[n-1].oldTo <= 2*context {
+			n++
+		}
+		hunk := blocks[:n]
+		blocks = blocks[n:]
+
+		first, last := hunk[0], hunk[n-1]
+		oldFrom := max(0, first.oldFrom-context)
+		oldTo := min(len(oldLines.lines), last.oldTo+context)
+		newFrom := first.newFrom - (first.oldFrom - oldFrom)
+		newTo := last.newTo + (oldTo - last.oldTo)
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldFrom, oldTo), hunkRange(newFrom, newTo))
+
+		line := oldFrom
+		for _, block := range hunk {
+			writeLines(&sb, " ", oldLines.lines[line:block.oldFrom])
+			writeLines(&sb, "-", oldLines.lines[block.oldFrom:block.oldTo])
+			writeLines(&sb, "+", newLines.lines[block.newFrom:block.newTo])
+			line = block.oldTo
+		}
+		writeLines(&sb, " ", oldLines.lines[line:oldTo])
+	}
+	return sb.String()
+}
+
+// hunkRange formats a line range the way unified diff hunk headers expect.
+func hunkRange(from, to int) string {
+	count := to - from
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", from)
+	case 1:
+		return fmt.Sprintf("%d", from+1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, count)
+}
+
+func writeLines(sb *strings.Builder, prefix string, lines []string) {
+	for _, line := range lines {
+		sb.WriteString(prefix)
+		sb.WriteString(line)
+		if !strings.HasSuffix(line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package textcompare
+
+import (
+	"testing"
+)
+
+func TestFormatUnified(t *testing.T) {
+	oldText := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+
+	// Test that distant changes are split into separate hunks with context
+	t.Run("Separate hunks", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Modified, Start: 8, Old: "three", New: "THREE", OldLen: 5, NewLen: 5},
+			{Op: Added, Start: 49, New: "eleven\n", NewLen: 7},
+		}
+		updatedText := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+		expected := "--- old\n+++ updated\n" +
+			"@@ -1,6 +1,6 @@\n one\n two\n-three\n+THREE\n four\n five\n six\n" +
+			"@@ -8,3 +8,4 @@\n eight\n nine\n ten\n+eleven\n"
+		if got := FormatUnified(oldText, updatedText, edits); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that a larger context merges nearby changes into one hunk
+	t.Run("Merged hunk", func(t *testing.T) {
+		edits := []Edit{
+			{Op: Modified, Start: 8, Old: "three", New: "THREE", OldLen: 5, NewLen: 5},
+			{Op: Modified, Start: 19, Old: "five", New: "FIVE", OldLen: 4, NewLen: 4},
+		}
+		updatedText := "one\ntwo\nTHREE\nfour\nFIVE\nsix\nseven\neight\nnine\nten\n"
+		expected := "--- old\n+++ updated\n" +
+			"@@ -2,5 +2,5 @@\n two\n-three\n+THREE\n four\n-five\n+FIVE\n six\n"
+		if got := FormatUnifiedContext(oldText, updatedText, edits, 1); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that deleted lines produce an empty new range
+	t.Run("Deleted lines", func(t *testing.T) {
+		edits := []Edit{{Op: Deleted, Start: 4, Old: "two\nthree\n", OldLen: 10}}
+		updatedText := "one\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+		expected := "--- old\n+++ updated\n" +
+			"@@ -2,2 +1,0 @@\n-two\n-three\n"
+		if got := FormatUnifiedContext(oldText, updatedText, edits, 0); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that a missing final newline is marked
+	t.Run("No newline at end of file", func(t *testing.T) {
+		edits := []Edit{{Op: Added, Start: 5, New: " world", NewLen: 6}}
+		expected := "--- old\n+++ updated\n" +
+			"@@ -1 +1 @@\n-hello\n\\ No newline at end of file\n+hello world\n\\ No newline at end of file\n"
+		if got := FormatUnified("hello", "hello world", edits); got != expected {
+			t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+		}
+	})
+
+	// Test that identical texts produce no output
+	t.Run("No changes", func(t *testing.T) {
+		if got := FormatUnified(oldText, oldText, nil); got != "" {
+			t.Errorf("Test failed. Expected no output Got: %q", got)
+		}
+	})
+}
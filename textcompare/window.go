@@ -0,0 +1,23 @@
+package textcompare
+
+import "unicode/utf8"
+
+const (
+	// MinSuggestedWindow is the smallest window size SuggestWindowSize returns.
+	MinSuggestedWindow = 1
+	// MaxSuggestedWindow is the largest window size SuggestWindowSize returns.
+	MaxSuggestedWindow = 16
+	// charsPerWindow is the number of characters of the shortest text that
+	// make the suggested window grow by one.
+	charsPerWindow = 64
+)
+
+// SuggestWindowSize picks a window size for comparing old and updated. The
+// window grows with the length of the shortest text, one character per 64,
+// and stays between MinSuggestedWindow and MaxSuggestedWindow, so short texts
+// are compared character by character and long ones skip shared content
+// faster.
+func SuggestWindowSize(old, updated string) int {
+	shortest := min(utf8.RuneCountInString(old), utf8.RuneCountInString(updated))
+	return min(max(shortest/charsPerWindow, MinSuggestedWindow), MaxSuggestedWindow)
+}
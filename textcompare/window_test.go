@@ -0,0 +1,33 @@
+package textcompare
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestWindowSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected int
+	}{
+		{"Empty texts", "", "", MinSuggestedWindow},
+		{"Tiny texts", "hello", "hello world", MinSuggestedWindow},
+		{"Medium texts", strings.Repeat("a", 500), strings.Repeat("b", 640), 7},
+		{"Large texts", strings.Repeat("a", 100000), strings.Repeat("b", 200000), MaxSuggestedWindow},
+		{"Shortest text decides", "hi", strings.Repeat("b", 100000), MinSuggestedWindow},
+		{"Multibyte characters", strings.Repeat("ñ", 128), strings.Repeat("ñ", 128), 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuggestWindowSize(tt.oldText, tt.updated)
+			if got != tt.expected {
+				t.Errorf("Test failed. Expected: %d Got: %d", tt.expected, got)
+			}
+			if got < MinSuggestedWindow || got > MaxSuggestedWindow {
+				t.Errorf("Test failed. Expected: a window in [%d, %d] Got: %d", MinSuggestedWindow, MaxSuggestedWindow, got)
+			}
+		})
+	}
+}
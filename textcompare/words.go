@@ -0,0 +1,53 @@
+package textcompare
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitWords splits text into words and the whitespace runs between them, so
+// that joining the tokens gives back the original text.
+func splitWords(text string) []string {
+	var tokens []string
+	start := 0
+	for i, r := range text {
+		if i > start && unicode.IsSpace(r) != isSpaceAt(text, start) {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+func isSpaceAt(text string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(text[i:])
+	return unicode.IsSpace(r)
+}
+
+// diffTokensAt compares two token sequences like diffTokens, but reports
+// edit positions and lengths in runes, positions being indexes into the old
+// text formed by joining the tokens, so that every edit starts and ends on a
+// token boundary.
+func diffTokensAt(old, updated []string) []Edit {
+	edits := diffTokens(old, updated, "")
+	offsets := make([]int, len(old)+1)
+	for i, token := range old {
+		offsets[i+1] = offsets[i] + utf8.RuneCountInString(token)
+	}
+	for i, edit := range edits {
+		edits[i].Start = offsets[edit.Start]
+		edits[i].OldLen = utf8.RuneCountInString(edit.Old)
+		edits[i].NewLen = utf8.RuneCountInString(edit.New)
+	}
+	return setNewStarts(edits)
+}
+
+// DiffWords compares two texts word by word. Words and the whitespace between
+// them are atomic units, so a replaced word is reported as a single
+// modification. Edit positions are rune indexes into old at word boundaries.
+func DiffWords(old, updated string) []Edit {
+	return diffTokensAt(splitWords(old), splitWords(updated))
+}
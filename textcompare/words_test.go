@@ -0,0 +1,64 @@
+package textcompare
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	// Test that words and whitespace runs are kept as separate tokens
+	expected := []string{"  ", "the", "  ", "cat", "\t", "sat", " "}
+	if got := splitWords("  the  cat\tsat "); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Test failed. Expected: %q Got: %q", expected, got)
+	}
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldText  string
+		updated  string
+		expected []Edit
+	}{
+		{
+			"Changed word",
+			"the cat sat on the mat",
+			"the dog sat on the mat",
+			[]Edit{{Op: Modified, Start: 4, NewStart: 4, Old: "cat", New: "dog", OldLen: 3, NewLen: 3}},
+		},
+		{
+			"Inserted word",
+			"the cat sat",
+			"the cat sat down",
+			[]Edit{{Op: Added, Start: 11, NewStart: 11, New: " down", NewLen: 5}},
+		},
+		{
+			"Deleted word",
+			"the cat sat down",
+			"the cat sat",
+			[]Edit{{Op: Deleted, Start: 11, NewStart: 11, Old: " down", OldLen: 5}},
+		},
+		{
+			"Changed accented word",
+			"el niño comió pan",
+			"el niño bebió pan",
+			[]Edit{{Op: Modified, Start: 8, NewStart: 8, Old: "comió", New: "bebió", OldLen: 5, NewLen: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := DiffWords(tt.oldText, tt.updated)
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("Test failed. Expected: %+v Got: %+v", tt.expected, edits)
+			}
+		})
+	}
+
+	// Test that word edits in the middle of a sentence rebuild the updated text
+	for _, updatedText := range []string{"the cat sat on the red mat", "the cat on the mat", "a cat sat under the mat"} {
+		oldText := "the cat sat on the mat"
+		if got := applyEdits(oldText, DiffWords(oldText, updatedText)); got != updatedText {
+			t.Errorf("Test failed. Expected: %s Got: %s", updatedText, got)
+		}
+	}
+}